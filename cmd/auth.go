@@ -0,0 +1,126 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/crypto"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	authKeyfile    string
+	authPAMService string
+	authPAMHelper  string
+)
+
+// authCmd represents the auth command
+var authCmd = &cobra.Command{
+	Use:   "auth [system|sudo|pam|keyfile|none]",
+	Short: "Show or choose how you are verified before the vault is unlocked",
+	Long: `Show or choose the authenticator that verifies you before the vault is
+unlocked. It is a check on top of the master password and is never used
+as an encryption key.
+
+  system   the system password, checked the native way of your OS
+           (sudo on Linux, dscl on macOS, the account database on Windows)
+  sudo     the system password, checked with sudo
+  pam      the system password, checked against a PAM service through a
+           helper such as pamtester (--pam-service, --pam-helper); other
+           helpers must be given as an absolute path to a file that only
+           root can change
+  keyfile  possession of a key file, e.g. on a USB stick (--keyfile); its
+           SHA-256 digest is recorded, so the file must not change
+  none     no extra check
+
+Without an argument the current authenticator is shown. The new one is
+tried once before it is saved, so a mistake cannot lock you out. Vaults
+without a master password of their own (see 'genp init') are unlocked with
+the system password itself, so it must be checked: they only accept
+system, sudo and pam.
+
+Examples:
+  genp auth
+  genp auth pam --pam-service login
+  genp auth keyfile --keyfile /media/usb/genp.key
+  genp auth none`,
+	Args:      cobra.MaximumNArgs(1),
+	ValidArgs: []string{crypto.AuthSystem, crypto.AuthSudo, crypto.AuthPAM, crypto.AuthKeyfile, crypto.AuthNone},
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := store.ActiveVaultInfo()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(args) == 0 {
+			color.New(color.FgGreen).Printf("Vault %s is verified by: ", info.Name)
+			color.Cyan("%s\n", info.Auth)
+			return
+		}
+
+		auth := crypto.AuthConfig{Method: args[0]}
+		switch auth.Method {
+		case crypto.AuthPAM:
+			auth.PAMService, auth.PAMHelper = authPAMService, authPAMHelper
+		case crypto.AuthKeyfile:
+			if authKeyfile == "" {
+				color.Red("Error: the keyfile authenticator needs --keyfile\n")
+				os.Exit(1)
+			}
+			path, err := filepath.Abs(authKeyfile)
+			if err != nil {
+				color.Red("Error: %v\n", err)
+				os.Exit(1)
+			}
+			digest, err := crypto.KeyfileDigest(path)
+			if err != nil {
+				color.Red("Error: %v\n", err)
+				os.Exit(1)
+			}
+			auth.Keyfile, auth.KeyfileSHA256 = path, digest
+		}
+		authenticator, err := crypto.NewAuthenticator(auth)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !info.OwnMasterPassword && !authenticator.NeedsPassword() {
+			color.Red("Error: vault %s is unlocked with your system password, which the %s authenticator does not check\n", info.Name, authenticator.Name())
+			color.Yellow("Run 'genp init' to give the vault a master password of its own first.\n")
+			os.Exit(1)
+		}
+
+		// Changing the check requires passing the current one
+		if _, err := store.UnlockVault(); err != nil {
+			color.Red("Error reading master password: %v\n", err)
+			os.Exit(1)
+		}
+		color.Cyan("Trying the %s authenticator...\n", authenticator.Name())
+		if _, err := crypto.PromptAndVerify("Enter system password: ", authenticator); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		confPath, err := store.SetAuth(auth, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Vault %s is now verified by: %s\n", info.Name, auth.Method)
+		syncIfLoggedIn(confPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(authCmd)
+
+	authCmd.Flags().StringVar(&authKeyfile, "keyfile", "", "Key file checked by the keyfile authenticator")
+	authCmd.Flags().StringVar(&authPAMService, "pam-service", crypto.DefaultPAMService, "PAM service used by the pam authenticator")
+	authCmd.Flags().StringVar(&authPAMHelper, "pam-helper", crypto.DefaultPAMHelper, "Helper program used by the pam authenticator")
+}
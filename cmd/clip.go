@@ -0,0 +1,124 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/clipboard"
+	"github.com/spf13/cobra"
+)
+
+var (
+	copyToClip  bool
+	clipTimeout time.Duration
+	clearAfter  time.Duration
+)
+
+// clipboardClearCmd runs in the background after --clip and clears the
+// clipboard once the timeout expires, unless the user copied something else.
+var clipboardClearCmd = &cobra.Command{
+	Use:    "clipboard-clear",
+	Short:  "Clear the clipboard if it still holds a copied secret",
+	Hidden: true,
+	Args:   cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+		if err != nil {
+			os.Exit(1)
+		}
+		fp, err := clipboard.ParseFingerprint(line)
+		if err != nil {
+			os.Exit(1)
+		}
+		time.Sleep(clearAfter)
+
+		backend, err := clipboard.Current()
+		if err != nil {
+			os.Exit(1)
+		}
+		if _, err := clipboard.ClearIfUnchanged(backend, fp); err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+// addClipFlags registers --clip and --clip-timeout on cmd.
+func addClipFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&copyToClip, "clip", "c", false, "Copy the secret to the clipboard instead of printing it")
+	cmd.Flags().DurationVar(&clipTimeout, "clip-timeout", 45*time.Second, "Clear the clipboard after this long (0 keeps it)")
+}
+
+// copyToClipboard places secret on the clipboard and schedules clearing it.
+func copyToClipboard(secret string) error {
+	backend, err := clipboard.Current()
+	if err != nil {
+		return err
+	}
+	if err := backend.Write(secret); err != nil {
+		return err
+	}
+
+	if clipTimeout <= 0 {
+		color.Green("[ok] Copied to the clipboard (%s).\n", backend.Name())
+		return nil
+	}
+	if err := scheduleClipboardClear(backend, secret); err != nil {
+		color.Yellow("[warn] Copied to the clipboard, but it will not be cleared automatically: %v\n", err)
+		return nil
+	}
+	color.Green("[ok] Copied to the clipboard (%s), clearing in %s.\n", backend.Name(), clipTimeout)
+	return nil
+}
+
+// scheduleClipboardClear starts a detached 'genp clipboard-clear' process so
+// the clipboard is cleared even after this command has exited. Only a keyed
+// fingerprint of the secret is handed over, through a pipe on its stdin.
+func scheduleClipboardClear(backend clipboard.Backend, secret string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	fp, err := clipboard.NewFingerprint(secret)
+	if err != nil {
+		return err
+	}
+
+	// The fingerprint fits in the pipe buffer, so it is written before the
+	// process starts and nothing is left to wait for afterwards.
+	stdin, w, err := os.Pipe()
+	if err != nil {
+		return fmt.Errorf("failed to start clipboard clearing: %w", err)
+	}
+	defer stdin.Close()
+	_, err = fmt.Fprintln(w, fp)
+	w.Close()
+	if err != nil {
+		return fmt.Errorf("failed to start clipboard clearing: %w", err)
+	}
+
+	proc := exec.Command(exe, "clipboard-clear", "--after", clipTimeout.String())
+	proc.Env = append(os.Environ(), clipboard.BackendEnv+"="+backend.Name())
+	proc.Stdin = stdin
+	// The OSC 52 backend writes its escape to stderr, which must stay
+	// connected to the terminal.
+	proc.Stderr = os.Stderr
+	detachProcess(proc)
+
+	if err := proc.Start(); err != nil {
+		return fmt.Errorf("failed to start clipboard clearing: %w", err)
+	}
+	return proc.Process.Release()
+}
+
+func init() {
+	rootCmd.AddCommand(clipboardClearCmd)
+
+	clipboardClearCmd.Flags().DurationVar(&clearAfter, "after", 45*time.Second, "Wait this long before clearing")
+}
@@ -4,11 +4,19 @@ Copyright © 2025 NAME HERE <EMAIL ADDRESS>
 package cmd
 
 import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mdxabu/genp/internal"
-	"github.com/mdxabu/genp/internal/github"
 	"github.com/mdxabu/genp/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -18,6 +26,24 @@ var (
 	includeUppercase bool
 	includeSpecial   bool
 	passwordLength   int
+	usePassphrase    bool
+	passphraseWords  int
+	wordSeparator    string
+	minLower         int
+	minUpper         int
+	minDigits        int
+	minSpecial       int
+	customCharset    string
+	symbolSet        string
+	excludeChars     string
+	noAmbiguous      bool
+	policyName       string
+	passwordTemplate string
+	usePronounceable bool
+	generateCount    int
+	noStore          bool
+	outputFormat     string
+	forceStore       bool
 )
 
 // createCmd represents the create command
@@ -31,37 +57,302 @@ You can specify which character types to include in your password:
   -A : Include uppercase letters (A-Z)
   -$ : Include special characters (!@#$&)
 
+Every selected class appears at least once. Use --min-lower, --min-upper,
+--min-digits and --min-special to require more characters of a class; a
+positive minimum also enables its class.
+
+--symbols replaces the special characters used by -$, --exclude removes
+characters from every class and --no-ambiguous drops look-alikes (0O1lI|).
+--charset replaces the alphabet entirely; the minimums then apply to the
+character classes found in it.
+
+--policy loads a named policy from the config (see 'genp policy'). Flags
+given on the command line override the values of the policy.
+
+--template fills a pattern instead of using a length: X is an uppercase
+letter, x a lowercase letter, # a digit, $ a special character, ? a letter
+or digit and * any of them. Escape a placeholder with \ to keep it literal;
+every other character is copied as is.
+
+With --passphrase a diceware-style passphrase is generated from an embedded
+wordlist instead. In that mode -A capitalizes every word, -0 adds a digit
+and -$ adds a special character to a random word. --charset, --exclude,
+--no-ambiguous and the --min-* flags do not apply to passphrases and are
+rejected.
+
+With --pronounceable the password is made of alternating consonants and
+vowels so it can be read aloud. -A capitalizes the first letter, while -0
+and -$ insert a digit or special character between two syllables.
+--exclude and --no-ambiguous remove characters from every set it uses;
+--charset and the --min-* flags are rejected.
+
+--count and --format switch to batch mode for scripts: the passwords are
+printed without colors or prompts and are never stored. json and csv
+include the length, character classes and entropy of every password;
+plain prints one password per line. --no-store skips the store prompt of
+a single interactive password.
+
+Storing under a name that is already taken asks whether to overwrite the
+entry, pick another name or keep both (the new one gets a -2 suffix).
+--force overwrites without asking. The overwritten password is kept in the
+entry's history.
+
+--clip copies the password to the clipboard instead of printing it and
+clears the clipboard after --clip-timeout, unless something else was
+copied in the meantime.
+
 Example:
-  genp create -0 -A -$ --length 16`,
+  genp create -0 -A -$ --length 16
+  genp create --length 20 --min-digits 2 --min-special 1
+  genp create -0 -A -$ --symbols "%*+-" --no-ambiguous
+  genp create --charset "abcdef0123456789" --length 32
+  genp create --policy aws-iam
+  genp create --template "Xx-####-????"
+  genp create --passphrase --words 6 --separator .
+  genp create --pronounceable -l 10 -0
+  genp create --count 50 --no-store --format json
+  genp create -0 -A -$ --clip --clip-timeout 20s`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if policyName != "" {
+			policy, err := store.GetPolicy(policyName)
+			if err != nil {
+				color.Red("Error: %v\n", err)
+				os.Exit(1)
+			}
+			applyPolicy(cmd, policy)
+		}
+		if err := checkModeFlags(); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if generateCount != 1 || outputFormat != "" {
+			if copyToClip {
+				color.Red("Error: --clip cannot be combined with --count or --format\n")
+				os.Exit(1)
+			}
+			if err := writeBatch(cmd.OutOrStdout()); err != nil {
+				exitGenerateError(err)
+			}
+			return
+		}
+
 		var userWish string
-		password := internal.GeneratePassword(passwordLength, includeNumbers, includeUppercase, includeSpecial)
-		color.New(color.FgGreen).Print("Generated Password: ")
-		color.New(color.FgCyan).Printf("%s\n", password)
+		password, entropy, err := generateFromFlags()
+		if err != nil {
+			exitGenerateError(err)
+		}
+		if copyToClip {
+			if err := copyToClipboard(password); err != nil {
+				color.Red("Error: failed to copy to the clipboard: %v\n", err)
+				os.Exit(1)
+			}
+		} else {
+			if usePassphrase {
+				color.New(color.FgGreen).Print("Generated Passphrase: ")
+			} else {
+				color.New(color.FgGreen).Print("Generated Password: ")
+			}
+			color.New(color.FgCyan).Printf("%s\n", password)
+		}
+		color.New(color.FgGreen).Print("Entropy: ")
+		color.New(color.FgCyan).Printf("%.1f bits\n", entropy)
+		if noStore {
+			return
+		}
+
 		color.New(color.FgYellow).Print("Do you want to store this password (y/n)?: ")
 		fmt.Scanln(&userWish)
 		if userWish == "y" {
-			confPath := store.StorepasswordLocally(password)
+			confPath := store.StorepasswordLocally(password, forceStore)
 			// Sync to GitHub vault if logged in and store succeeded
-			if confPath != "" && github.IsLoggedIn() {
-				color.Cyan("Syncing to GitHub vault...\n")
-				if err := github.SyncConfigToVaultIfLoggedIn(confPath); err != nil {
-					color.Yellow("[warn] Failed to sync to GitHub vault: %v\n", err)
-					color.Yellow("  Your password is still stored locally.\n")
-				} else {
-					color.Green("[ok] Synced to GitHub genp-vault repository.\n")
-				}
+			if confPath != "" {
+				syncIfLoggedIn(confPath)
 			}
 		}
 
 	},
 }
 
+// generatedPassword is one item of machine-readable 'create' output.
+type generatedPassword struct {
+	Password string   `json:"password"`
+	Length   int      `json:"length"`
+	Classes  []string `json:"classes"`
+	Entropy  float64  `json:"entropy"`
+}
+
+// writeBatch generates --count secrets and writes them to w in the
+// selected --format without prompting.
+func writeBatch(w io.Writer) error {
+	if generateCount < 1 {
+		return fmt.Errorf("--count must be at least 1")
+	}
+
+	items := make([]generatedPassword, 0, generateCount)
+	for i := 0; i < generateCount; i++ {
+		password, entropy, err := generateFromFlags()
+		if err != nil {
+			return err
+		}
+		classes := internal.CharacterClasses(password)
+		if usePassphrase {
+			// The separator is not part of what the options produce
+			classes = passphraseOptions().Classes()
+		}
+		items = append(items, generatedPassword{
+			Password: password,
+			Length:   len(password),
+			Classes:  classes,
+			Entropy:  math.Round(entropy*100) / 100,
+		})
+	}
+
+	switch outputFormat {
+	case "", "plain":
+		for _, item := range items {
+			fmt.Fprintln(w, item.Password)
+		}
+		return nil
+
+	case "json":
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(items)
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"password", "length", "classes", "entropy"})
+		for _, item := range items {
+			cw.Write([]string{
+				item.Password,
+				strconv.Itoa(item.Length),
+				strings.Join(item.Classes, "+"),
+				strconv.FormatFloat(item.Entropy, 'f', 2, 64),
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+
+	default:
+		return fmt.Errorf("unknown format %q (use json, csv or plain)", outputFormat)
+	}
+}
+
+// exitGenerateError reports a generation failure and exits non-zero.
+// Nothing produced by a failed random source is ever shown or stored.
+func exitGenerateError(err error) {
+	color.Red("Error: %v\n", err)
+	if errors.Is(err, internal.ErrRandomSource) {
+		color.Red("Refusing to display or store a password generated without a working random source.\n")
+	}
+	os.Exit(1)
+}
+
+// generateFromFlags produces a secret in the mode selected by the flags
+// and returns its entropy in bits.
+func generateFromFlags() (string, float64, error) {
+	switch {
+	case usePassphrase:
+		opts := passphraseOptions()
+		password, err := internal.GeneratePassphrase(opts)
+		return password, internal.PassphraseEntropy(opts), err
+
+	case usePronounceable:
+		opts := internal.PronounceableOptions{
+			Length:     passwordLength,
+			Capitalize: includeUppercase,
+			AddDigit:   includeNumbers,
+			AddSymbol:  includeSpecial,
+			Symbols:    symbolSet,
+
+			Exclude:     excludeChars,
+			NoAmbiguous: noAmbiguous,
+		}
+		password, err := internal.GeneratePronounceable(opts)
+		return password, internal.PronounceableEntropy(opts), err
+	}
+
+	if passwordTemplate != "" {
+		password, err := internal.GenerateFromTemplate(passwordTemplate, passwordOptions())
+		if err != nil {
+			return "", 0, err
+		}
+		entropy, err := internal.TemplateEntropy(passwordTemplate, passwordOptions())
+		return password, entropy, err
+	}
+
+	password, err := internal.GenerateWithOptions(passwordOptions())
+	if err != nil {
+		return "", 0, err
+	}
+	entropy, err := internal.PasswordEntropy(passwordOptions())
+	return password, entropy, err
+}
+
+// passphraseOptions returns the passphrase options selected by the flags.
+func passphraseOptions() internal.PassphraseOptions {
+	return internal.PassphraseOptions{
+		Words:      passphraseWords,
+		Separator:  wordSeparator,
+		Capitalize: includeUppercase,
+		AddDigit:   includeNumbers,
+		AddSymbol:  includeSpecial,
+		Symbols:    symbolSet,
+	}
+}
+
+// checkModeFlags rejects password flags, set directly or by a policy, that
+// the selected mode would ignore.
+func checkModeFlags() error {
+	var mode string
+	switch {
+	case usePassphrase:
+		mode = "--passphrase"
+	case usePronounceable:
+		mode = "--pronounceable"
+	default:
+		return nil
+	}
+
+	unused := map[string]bool{
+		"--charset":     customCharset != "",
+		"--min-lower":   minLower != 0,
+		"--min-upper":   minUpper != 0,
+		"--min-digits":  minDigits != 0,
+		"--min-special": minSpecial != 0,
+	}
+	if usePassphrase {
+		unused["--exclude"] = excludeChars != ""
+		unused["--no-ambiguous"] = noAmbiguous
+	}
+	names := make([]string, 0, len(unused))
+	for name, set := range unused {
+		if set {
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		sort.Strings(names)
+		return fmt.Errorf("%s cannot be combined with %s", strings.Join(names, ", "), mode)
+	}
+	return nil
+}
+
 func init() {
 	rootCmd.AddCommand(createCmd)
 
-	createCmd.Flags().BoolVarP(&includeNumbers, "numbers", "0", false, "Include numbers in password")
-	createCmd.Flags().BoolVarP(&includeUppercase, "uppercase", "A", false, "Include uppercase letters in password")
-	createCmd.Flags().BoolVarP(&includeSpecial, "special", "$", false, "Include special characters in password")
-	createCmd.Flags().IntVarP(&passwordLength, "length", "l", 12, "Length of the password")
+	addPasswordFlags(createCmd)
+	createCmd.Flags().StringVar(&policyName, "policy", "", "Use a named password policy from the config")
+	createCmd.Flags().BoolVarP(&usePassphrase, "passphrase", "p", false, "Generate a diceware-style passphrase instead of a password")
+	createCmd.Flags().IntVarP(&passphraseWords, "words", "w", 6, "Number of words in the passphrase")
+	createCmd.Flags().StringVar(&wordSeparator, "separator", "-", "Separator placed between passphrase words")
+	createCmd.Flags().StringVar(&passwordTemplate, "template", "", "Generate the password from a pattern such as \"Xx-####-????\"")
+	createCmd.Flags().BoolVar(&usePronounceable, "pronounceable", false, "Generate a password of alternating consonant/vowel syllables")
+	createCmd.MarkFlagsMutuallyExclusive("passphrase", "template", "pronounceable")
+	createCmd.Flags().IntVarP(&generateCount, "count", "n", 1, "Number of passwords to generate")
+	createCmd.Flags().BoolVar(&noStore, "no-store", false, "Do not offer to store the password")
+	createCmd.Flags().StringVar(&outputFormat, "format", "", "Print passwords without prompts as json, csv or plain")
+	createCmd.Flags().BoolVar(&forceStore, "force", false, "Overwrite an existing entry with the same name without asking")
+	addClipFlags(createCmd)
 }
@@ -0,0 +1,12 @@
+//go:build !unix
+
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import "os/exec"
+
+// detachProcess is a no-op on platforms without sessions; the child process
+// already outlives its parent there.
+func detachProcess(cmd *exec.Cmd) {}
@@ -0,0 +1,17 @@
+//go:build unix
+
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// detachProcess starts cmd in its own session so it survives the terminal
+// that launched genp being closed.
+func detachProcess(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"errors"
+	"os"
+	"runtime"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/crypto"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// clearFieldInput clears a field when typed at an edit prompt.
+const clearFieldInput = "-"
+
+var errPasswordMismatch = errors.New("passwords do not match")
+
+// editCmd represents the edit command
+var editCmd = &cobra.Command{
+	Use:   "edit <name>",
+	Short: "Edit a stored entry",
+	Long: `Edit the password, username, URLs, notes and tags of a stored entry.
+
+Each field is shown with its current value: press Enter to keep it, type a
+new value to replace it or type "-" to clear it. The changes are saved after
+a confirmation, which --yes skips. When logged in to GitHub the change is
+synced to the genp-vault repository.
+
+Names are resolved as by 'genp get': a name without folders also finds an
+entry in a folder if it is the only one with that name, and --folder and
+--tag narrow the search down.
+
+Examples:
+  genp edit github`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, entry, err := store.FindEntry(args[0], entryFilter())
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		key, err := store.UnlockVault()
+		if err != nil {
+			color.Red("Error reading master password: %v\n", err)
+			os.Exit(1)
+		}
+		// Unlocking an older vault re-encrypts its entries, so read the
+		// entry again to edit the version now on disk
+		if name, entry, err = store.FindEntry(name, store.Filter{}); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		details, err := entry.Decrypt(key)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		color.Cyan("Editing %s (Enter keeps a value, \"-\" clears it):\n", name)
+		details.Username = editField("  Username", details.Username)
+		details.URLs = store.SplitList(editField("  URL(s), comma separated", strings.Join(details.URLs, ", ")))
+		details.Notes = editField("  Notes", details.Notes)
+		details.Tags = store.SplitList(editField("  Tags, comma separated", strings.Join(details.Tags, ", ")))
+
+		if askYesNo("Change the password") {
+			newPassword, err := promptNewPassword()
+			if err != nil {
+				color.Red("Error: %v\n", err)
+				os.Exit(1)
+			}
+			details.Password = newPassword
+		}
+
+		if !confirm("Save changes to " + name) {
+			color.Yellow("Nothing changed.\n")
+			return
+		}
+
+		read := *entry
+		if err := entry.Update(details, key); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		confPath, err := store.UpdateEntry(name, &read, entry, runtime.GOOS)
+		if errors.Is(err, store.ErrEntryChanged) {
+			color.Red("Error: %v\n", err)
+			color.Yellow("Nothing was saved; run 'genp edit %s' again to edit the current version.\n", name)
+			os.Exit(1)
+		}
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Updated %s\n", name)
+		syncIfLoggedIn(confPath)
+	},
+}
+
+// editField prompts for a new value of a field, showing the current one.
+func editField(label, current string) string {
+	input := store.PromptLine(label + " [" + current + "]: ")
+	switch input {
+	case "":
+		return current
+	case clearFieldInput:
+		return ""
+	default:
+		return input
+	}
+}
+
+// promptNewPassword reads a new password twice without echoing it.
+func promptNewPassword() (string, error) {
+	password, err := crypto.PromptForPassword("New password: ")
+	if err != nil {
+		return "", err
+	}
+	again, err := crypto.PromptForPassword("Repeat new password: ")
+	if err != nil {
+		return "", err
+	}
+	if password != again {
+		return "", errPasswordMismatch
+	}
+	return password, nil
+}
+
+func init() {
+	rootCmd.AddCommand(editCmd)
+
+	addFilterFlags(editCmd)
+	editCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation before saving")
+}
@@ -0,0 +1,117 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	getField   string
+	getVersion int
+)
+
+// getCmd represents the get command
+var getCmd = &cobra.Command{
+	Use:   "get <name>",
+	Short: "Display a single stored entry",
+	Long: `Decrypt and print a single field of one stored entry.
+
+Only the requested field is decrypted and it is printed on its own, so the
+output can be piped to other programs. URLs are stored unencrypted and are
+printed without asking for the master password.
+
+With --clip the value is copied to the clipboard instead of printed, and
+the clipboard is cleared after --clip-timeout unless something else was
+copied in the meantime.
+
+--version prints an earlier password instead, numbered as listed by
+'genp history'.
+
+Names can contain folders, such as work/aws/prod. A name without folders
+also finds an entry in a folder if it is the only one with that name;
+--folder and --tag narrow the search down.
+
+Examples:
+  genp get github
+  genp get github --field username
+  genp get github --field url
+  genp get github --version 1
+  genp get work/aws/prod
+  genp get prod --folder work/aws
+  genp get github --clip`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+
+		switch getField {
+		case store.FieldPassword, store.FieldUsername, store.FieldURL, store.FieldNotes:
+		default:
+			color.Red("Error: unknown field %q (use password, username, url or notes)\n", getField)
+			os.Exit(1)
+		}
+		if getVersion != 0 && getField != store.FieldPassword {
+			color.Red("Error: --version only applies to the password field\n")
+			os.Exit(1)
+		}
+
+		name, entry, err := store.FindEntry(name, entryFilter())
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if getVersion != 0 {
+			if _, err = entry.Version(getVersion); err != nil {
+				color.Red("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var key *store.VaultKey
+		if getField != store.FieldURL {
+			key, err = store.UnlockVaultReadOnly()
+			if err != nil {
+				color.Red("Error reading master password: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		var value string
+		if getVersion != 0 {
+			value, err = entry.VersionPassword(getVersion, key)
+		} else {
+			value, err = entry.Field(getField, key)
+		}
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if value == "" {
+			color.Yellow("%s has no %s stored.\n", name, getField)
+			return
+		}
+		if copyToClip {
+			if err := copyToClipboard(value); err != nil {
+				color.Red("Error: failed to copy to the clipboard: %v\n", err)
+				os.Exit(1)
+			}
+			return
+		}
+		fmt.Println(value)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(getCmd)
+
+	addClipFlags(getCmd)
+	addFilterFlags(getCmd)
+	getCmd.Flags().StringVarP(&getField, "field", "f", store.FieldPassword, "Field to print: password, username, url or notes")
+	getCmd.Flags().IntVar(&getVersion, "version", 0, "Print an earlier password, as listed by 'genp history'")
+}
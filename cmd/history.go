@@ -0,0 +1,70 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history <name>",
+	Short: "List the earlier versions of an entry",
+	Long: `List when the earlier versions of a stored entry were replaced.
+
+Version 1 is the version replaced most recently. Print its password with
+'genp get <name> --version N' or make it current again with
+'genp restore <name> --version N'. The versions stay encrypted, so no
+master password is needed to list them.
+
+genp keeps the last 10 versions of every entry. Set history_retention in
+the settings section of genp.yaml to keep a different number:
+
+  settings:
+    history_retention: 5
+
+Names are resolved as by 'genp get': a name without folders also finds an
+entry in a folder if it is the only one with that name, and --folder and
+--tag narrow the search down.
+
+Examples:
+  genp history github`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, entry, err := store.FindEntry(args[0], entryFilter())
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		color.New(color.FgGreen).Printf("%s\n", name)
+		current := "-"
+		if !entry.Updated.IsZero() {
+			current = entry.Updated.Local().Format(time.DateTime)
+		}
+		color.New(color.FgCyan).Printf("  %-8s ", "current")
+		color.Yellow("set %s\n", current)
+
+		if len(entry.History) == 0 {
+			color.Yellow("  No earlier versions.\n")
+			return
+		}
+		for version := 1; version <= len(entry.History); version++ {
+			item, _ := entry.Version(version)
+			color.New(color.FgCyan).Printf("  %-8d ", version)
+			color.Yellow("replaced %s\n", item.ChangedAt.Local().Format(time.DateTime))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+
+	addFilterFlags(historyCmd)
+}
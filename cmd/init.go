@@ -0,0 +1,92 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/crypto"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var initOSVerify bool
+
+// initCmd represents the init command
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set a master password for the vault",
+	Long: `Protect the vault with a master password of its own instead of your
+system password.
+
+Vaults created before this command existed are encrypted with your system
+(login) password, so they become unreadable when that password changes and
+cannot be unlocked where it cannot be verified, e.g. without sudo. After
+'genp init' the vault is unlocked with its master password only. A new
+vault asks for its master password when the first password is stored.
+
+If the vault already has entries you are asked for the system password it
+was encrypted with first. It is not checked against the operating system,
+so it also works after your login password was changed.
+
+With --os-verify genp still asks for the system password, verified against
+the operating system, as an extra check before the master password. It is
+never used as a key. Change the master password later with 'genp passwd'.
+
+Examples:
+  genp init
+  genp init --os-verify
+  genp --vault work init`,
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := store.ActiveVaultInfo()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if info.OwnMasterPassword {
+			color.Red("Error: %v %s (change it with 'genp passwd')\n", store.ErrMasterPasswordSet, info.Name)
+			os.Exit(1)
+		}
+
+		var key *store.VaultKey
+		if info.Entries > 0 {
+			color.Cyan("Vault %s is encrypted with your system password.\n", info.Name)
+			systemPassword, err := crypto.PromptForPassword("Enter the system password the vault was encrypted with: ")
+			if err != nil {
+				color.Red("Error: %v\n", err)
+				os.Exit(1)
+			}
+			if key, err = store.UnlockWithPassword(systemPassword); err != nil {
+				color.Red("Error: %v\n", err)
+				os.Exit(1)
+			}
+		}
+
+		color.Cyan("Choose the master password of vault %s.\n", info.Name)
+		masterPassword, err := promptNewPassword()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		confPath, err := store.InitMasterPassword(key, masterPassword, initOSVerify, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Vault %s is now protected by its own master password\n", info.Name)
+		if initOSVerify {
+			color.Green("[ok] Your system password will be verified before the master password\n")
+		}
+		syncIfLoggedIn(confPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+
+	initCmd.Flags().BoolVar(&initOSVerify, "os-verify", false, "Also verify the system password when unlocking the vault")
+}
@@ -85,9 +85,19 @@ var logoutCmd = &cobra.Command{
 }
 
 func setupVaultAndSync(token string) {
+	target, err := store.ActiveSyncTarget()
+	if err != nil {
+		color.Yellow("[warn] Could not read the vault sync target: %v\n", err)
+		return
+	}
+	if target.Disabled {
+		color.Yellow("Sync is disabled for vault %s.\n", store.ActiveVault())
+		return
+	}
+
 	// Create or get the vault repo
-	color.Cyan("Setting up genp-vault repository...\n")
-	repo, err := github.CreateOrGetVaultRepo(token)
+	color.Cyan("Setting up vault repository...\n")
+	repo, err := github.CreateOrGetRepo(token, target.Repo)
 	if err != nil {
 		color.Yellow("[warn] Could not set up vault repository: %v\n", err)
 		color.Yellow("  You can try again later with 'genp sync'. Passwords will still be stored locally.\n")
@@ -105,11 +115,11 @@ func setupVaultAndSync(token string) {
 	}
 
 	color.Cyan("Pushing existing passwords to vault...\n")
-	if err := github.SyncConfigToVault(confPath); err != nil {
+	if err := github.SyncConfigToRepo(confPath, target.Repo, target.Path); err != nil {
 		color.Yellow("[warn] Failed to push existing passwords: %v\n", err)
 		color.Yellow("  You can retry with 'genp sync'.\n")
 	} else {
-		color.Green("[ok] Existing passwords synced to genp-vault repository.\n")
+		color.Green("[ok] Existing passwords synced to %s.\n", repo.FullName)
 	}
 }
 
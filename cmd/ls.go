@@ -0,0 +1,143 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	filterFolder string
+	filterTags   []string
+)
+
+// lsCmd represents the ls command
+var lsCmd = &cobra.Command{
+	Use:     "ls [folder]",
+	Aliases: []string{"list"},
+	Short:   "List stored entries as a folder tree",
+	Long: `List the names of stored entries as a tree, without decrypting anything.
+
+Entry names can contain folders separated by "/", such as work/aws/prod.
+Give a folder to list only the entries inside it, and --tag (repeatable)
+to list only entries carrying every given tag. Tags are shown next to
+each entry.
+
+Examples:
+  genp ls
+  genp ls work/aws
+  genp ls --tag prod --tag root`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		filter := store.Filter{Tags: filterTags}
+		if len(args) == 1 {
+			filter.Folder = args[0]
+		}
+
+		entries, err := store.GetAllEntries()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		names := filter.Names(entries)
+		if len(names) == 0 {
+			color.Yellow("No entries match.\n")
+			return
+		}
+
+		root := newTreeNode()
+		for _, name := range names {
+			root.add(strings.Split(name, store.FolderSeparator), entries[name])
+		}
+		root.print("", true)
+	},
+}
+
+// treeNode is a folder or entry in the tree printed by 'genp ls'. A name
+// can be both, e.g. when "work" and "work/github" are stored.
+type treeNode struct {
+	children map[string]*treeNode
+	entry    *store.Entry
+}
+
+func newTreeNode() *treeNode {
+	return &treeNode{children: make(map[string]*treeNode)}
+}
+
+// add inserts an entry at the path given by parts.
+func (n *treeNode) add(parts []string, entry *store.Entry) {
+	child, ok := n.children[parts[0]]
+	if !ok {
+		child = newTreeNode()
+		n.children[parts[0]] = child
+	}
+	if len(parts) == 1 {
+		child.entry = entry
+		return
+	}
+	child.add(parts[1:], entry)
+}
+
+// print writes the children of n, sorted by name, below prefix. The
+// top level is printed without branch lines.
+func (n *treeNode) print(prefix string, top bool) {
+	names := make([]string, 0, len(n.children))
+	for name := range n.children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for i, name := range names {
+		child := n.children[name]
+
+		branch, indent := "├── ", "│   "
+		if i == len(names)-1 {
+			branch, indent = "└── ", "    "
+		}
+		if top {
+			branch, indent = "", ""
+		}
+
+		color.New(color.FgWhite).Print(prefix + branch)
+		switch {
+		case len(child.children) > 0 && child.entry != nil:
+			color.New(color.FgCyan).Print(name + store.FolderSeparator)
+			color.New(color.FgGreen).Print(" (also an entry)")
+		case len(child.children) > 0:
+			color.New(color.FgCyan).Print(name + store.FolderSeparator)
+		default:
+			color.New(color.FgGreen).Print(name)
+		}
+		if child.entry != nil && len(child.entry.Tags) > 0 {
+			color.New(color.FgYellow).Printf("  [%s]", strings.Join(child.entry.Tags, ", "))
+		}
+		color.New(color.FgWhite).Println()
+
+		child.print(prefix+indent, false)
+	}
+}
+
+// addFilterFlags registers the --folder and --tag flags shared by 'show'
+// and the commands that look up a single entry.
+func addFilterFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&filterFolder, "folder", "", "Only consider entries inside this folder, e.g. work/aws")
+	cmd.Flags().StringSliceVar(&filterTags, "tag", nil, "Only consider entries carrying this tag (repeatable)")
+}
+
+// entryFilter returns the filter selected with --folder and --tag.
+func entryFilter() store.Filter {
+	return store.Filter{Folder: filterFolder, Tags: filterTags}
+}
+
+func init() {
+	rootCmd.AddCommand(lsCmd)
+
+	lsCmd.Flags().StringSliceVar(&filterTags, "tag", nil, "Only list entries carrying this tag (repeatable)")
+}
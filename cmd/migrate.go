@@ -0,0 +1,66 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var migrateCheck bool
+
+// migrateCmd represents the migrate command
+var migrateCmd = &cobra.Command{
+	Use:   "migrate",
+	Short: "Upgrade genp.yaml to the current file format",
+	Long: `Upgrade genp.yaml written by an older version of genp to the current
+file format, one version at a time. The original file is kept in
+genp.yaml.bak.
+
+genp also upgrades old files automatically when it reads them; this command
+lets you do it explicitly. With --check it only lists the steps that would
+run and changes nothing. Files written by a newer version of genp are never
+touched.
+
+Examples:
+  genp migrate --check
+  genp migrate`,
+	Run: func(cmd *cobra.Command, args []string) {
+		plan, err := store.PlanMigration()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !plan.NeedsMigration() {
+			color.Green("[ok] %s is up to date (format version %d)\n", plan.Path, plan.To)
+			return
+		}
+
+		color.Cyan("%s uses format version %d; the current version is %d.\n", plan.Path, plan.From, plan.To)
+		for _, step := range plan.Steps {
+			color.Yellow("  %s\n", step)
+		}
+		if migrateCheck {
+			return
+		}
+
+		plan, err = store.Migrate(runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Migrated %s to format version %d\n", plan.Path, plan.To)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(migrateCmd)
+
+	migrateCmd.Flags().BoolVar(&migrateCheck, "check", false, "Only list the migration steps, without changing the file")
+}
@@ -0,0 +1,67 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// mvCmd represents the mv command
+var mvCmd = &cobra.Command{
+	Use:     "mv <old-name> <new-name>",
+	Aliases: []string{"rename"},
+	Short:   "Rename a stored entry",
+	Long: `Rename a stored entry. An existing entry is never replaced. Entries are
+encrypted for their name, so renaming one asks for the master password.
+
+You are asked for confirmation unless --yes is given. When logged in to
+GitHub the change is synced to the genp-vault repository.
+
+The old name is resolved as by 'genp get': a name without folders also finds an
+entry in a folder if it is the only one with that name, and --folder and
+--tag narrow the search down.
+
+Examples:
+  genp mv github github-personal`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		newName := args[1]
+
+		oldName, _, err := store.FindEntry(args[0], entryFilter())
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !confirm("Rename " + oldName + " to " + newName) {
+			color.Yellow("Nothing renamed.\n")
+			return
+		}
+
+		// The entry is re-encrypted for its new name
+		key, err := store.UnlockVault()
+		if err != nil {
+			color.Red("Error reading master password: %v\n", err)
+			os.Exit(1)
+		}
+		confPath, err := store.RenameEntry(oldName, newName, key, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Renamed %s to %s\n", oldName, newName)
+		syncIfLoggedIn(confPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(mvCmd)
+
+	addFilterFlags(mvCmd)
+	mvCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+}
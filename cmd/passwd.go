@@ -0,0 +1,65 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// passwdCmd represents the passwd command
+var passwdCmd = &cobra.Command{
+	Use:   "passwd",
+	Short: "Change the master password of the vault",
+	Long: `Change the master password of the vault.
+
+Only the vault key is re-encrypted with the new password, so this is quick
+however many entries the vault has. Vaults still unlocked with the system
+password need a master password first; set one with 'genp init'. When
+logged in to GitHub the vault is synced afterwards.
+
+Examples:
+  genp passwd
+  genp --vault work passwd`,
+	Run: func(cmd *cobra.Command, args []string) {
+		info, err := store.ActiveVaultInfo()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !info.OwnMasterPassword {
+			color.Red("Error: %v %s (set one with 'genp init')\n", store.ErrNoMasterPassword, info.Name)
+			os.Exit(1)
+		}
+
+		key, err := store.UnlockVault()
+		if err != nil {
+			color.Red("Error reading master password: %v\n", err)
+			os.Exit(1)
+		}
+
+		color.Cyan("Choose the new master password of vault %s.\n", info.Name)
+		newPassword, err := promptNewPassword()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		confPath, err := store.ChangeMasterPassword(key, newPassword, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Changed the master password of vault %s\n", info.Name)
+		syncIfLoggedIn(confPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(passwdCmd)
+}
@@ -0,0 +1,252 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// policyCmd represents the policy command
+var policyCmd = &cobra.Command{
+	Use:   "policy",
+	Short: "Manage named password policies",
+	Long: `Manage named password policies stored in genp.yaml.
+
+A policy records the length, character classes, minimum counts and
+excluded characters required by a site, so passwords can be generated
+with 'genp create --policy <name>' instead of repeating the flags.
+
+Examples:
+  genp policy add aws-iam -l 24 -0 -A -$ --min-digits 2
+  genp policy list
+  genp policy remove aws-iam`,
+}
+
+// policyListCmd represents the policy list command
+var policyListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List stored password policies",
+	Run: func(cmd *cobra.Command, args []string) {
+		policies, err := store.GetPolicies()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(policies) == 0 {
+			color.Yellow("No policies defined yet. Add one with 'genp policy add <name>'.\n")
+			return
+		}
+
+		names := make([]string, 0, len(policies))
+		for name := range policies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		color.Cyan("=== Password Policies ===\n")
+		for _, name := range names {
+			color.New(color.FgGreen).Printf("%s: ", name)
+			color.Yellow("%s\n", describePolicy(policies[name]))
+		}
+	},
+}
+
+// policyAddCmd represents the policy add command
+var policyAddCmd = &cobra.Command{
+	Use:   "add <name>",
+	Short: "Add or replace a password policy",
+	Long: `Add or replace a named password policy using the same flags as 'genp create'.
+
+Example:
+  genp policy add aws-iam -l 24 -0 -A -$ --min-digits 2 --exclude "'\""`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		policy := policyFromFlags()
+
+		// Reject policies that can never produce a password
+		if err := passwordOptions().Validate(); err != nil {
+			color.Red("Error: policy cannot generate passwords: %v\n", err)
+			os.Exit(1)
+		}
+
+		confPath, replaced, err := store.SavePolicy(args[0], policy, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if replaced {
+			color.Green("[ok] Updated policy %q in %s\n", args[0], confPath)
+		} else {
+			color.Green("[ok] Added policy %q to %s\n", args[0], confPath)
+		}
+	},
+}
+
+// policyRemoveCmd represents the policy remove command
+var policyRemoveCmd = &cobra.Command{
+	Use:     "remove <name>",
+	Aliases: []string{"rm"},
+	Short:   "Remove a password policy",
+	Args:    cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := store.RemovePolicy(args[0], runtime.GOOS); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Removed policy %q\n", args[0])
+	},
+}
+
+// addPasswordFlags registers the password generation flags shared by
+// 'create' and 'policy add'.
+func addPasswordFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVarP(&includeNumbers, "numbers", "0", false, "Include numbers in password")
+	cmd.Flags().BoolVarP(&includeUppercase, "uppercase", "A", false, "Include uppercase letters in password")
+	cmd.Flags().BoolVarP(&includeSpecial, "special", "$", false, "Include special characters in password")
+	cmd.Flags().IntVarP(&passwordLength, "length", "l", 12, "Length of the password")
+	cmd.Flags().IntVar(&minLower, "min-lower", 0, "Minimum number of lowercase letters")
+	cmd.Flags().IntVar(&minUpper, "min-upper", 0, "Minimum number of uppercase letters")
+	cmd.Flags().IntVar(&minDigits, "min-digits", 0, "Minimum number of digits")
+	cmd.Flags().IntVar(&minSpecial, "min-special", 0, "Minimum number of special characters")
+	cmd.Flags().StringVar(&customCharset, "charset", "", "Custom alphabet that replaces the selected character classes")
+	cmd.Flags().StringVar(&symbolSet, "symbols", "", "Special characters to use instead of !@#$&")
+	cmd.Flags().StringVar(&excludeChars, "exclude", "", "Characters that must never appear in the password")
+	cmd.Flags().BoolVar(&noAmbiguous, "no-ambiguous", false, "Exclude look-alike characters (0O1lI|)")
+}
+
+// passwordOptions builds generator options from the password flags.
+func passwordOptions() internal.PasswordOptions {
+	return internal.PasswordOptions{
+		Length:     passwordLength,
+		Numbers:    includeNumbers,
+		Uppercase:  includeUppercase,
+		Special:    includeSpecial,
+		MinLower:   minLower,
+		MinUpper:   minUpper,
+		MinDigits:  minDigits,
+		MinSpecial: minSpecial,
+
+		Charset:     customCharset,
+		Symbols:     symbolSet,
+		Exclude:     excludeChars,
+		NoAmbiguous: noAmbiguous,
+	}
+}
+
+// policyFromFlags captures the password flags as a storable policy.
+func policyFromFlags() store.Policy {
+	return store.Policy{
+		Length:     passwordLength,
+		Numbers:    includeNumbers,
+		Uppercase:  includeUppercase,
+		Special:    includeSpecial,
+		MinLower:   minLower,
+		MinUpper:   minUpper,
+		MinDigits:  minDigits,
+		MinSpecial: minSpecial,
+
+		Charset:     customCharset,
+		Symbols:     symbolSet,
+		Exclude:     excludeChars,
+		NoAmbiguous: noAmbiguous,
+	}
+}
+
+// applyPolicy copies the policy into the password flags that were not
+// set explicitly on the command line.
+func applyPolicy(cmd *cobra.Command, p store.Policy) {
+	flags := cmd.Flags()
+	if !flags.Changed("length") {
+		passwordLength = p.Length
+	}
+	if !flags.Changed("numbers") {
+		includeNumbers = p.Numbers
+	}
+	if !flags.Changed("uppercase") {
+		includeUppercase = p.Uppercase
+	}
+	if !flags.Changed("special") {
+		includeSpecial = p.Special
+	}
+	if !flags.Changed("min-lower") {
+		minLower = p.MinLower
+	}
+	if !flags.Changed("min-upper") {
+		minUpper = p.MinUpper
+	}
+	if !flags.Changed("min-digits") {
+		minDigits = p.MinDigits
+	}
+	if !flags.Changed("min-special") {
+		minSpecial = p.MinSpecial
+	}
+	if !flags.Changed("charset") {
+		customCharset = p.Charset
+	}
+	if !flags.Changed("symbols") {
+		symbolSet = p.Symbols
+	}
+	if !flags.Changed("exclude") {
+		excludeChars = p.Exclude
+	}
+	if !flags.Changed("no-ambiguous") {
+		noAmbiguous = p.NoAmbiguous
+	}
+}
+
+// describePolicy renders a one-line summary of a policy.
+func describePolicy(p store.Policy) string {
+	parts := []string{fmt.Sprintf("length %d", p.Length)}
+	if p.Charset != "" {
+		parts = append(parts, fmt.Sprintf("charset %q", p.Charset))
+	} else {
+		classes := []string{"lowercase"}
+		if p.Uppercase || p.MinUpper > 0 {
+			classes = append(classes, "uppercase")
+		}
+		if p.Numbers || p.MinDigits > 0 {
+			classes = append(classes, "digits")
+		}
+		if p.Special || p.MinSpecial > 0 {
+			classes = append(classes, "special")
+		}
+		parts = append(parts, strings.Join(classes, "+"))
+	}
+	for _, m := range []struct {
+		label string
+		n     int
+	}{{"lower", p.MinLower}, {"upper", p.MinUpper}, {"digits", p.MinDigits}, {"special", p.MinSpecial}} {
+		if m.n > 0 {
+			parts = append(parts, fmt.Sprintf("min %s %d", m.label, m.n))
+		}
+	}
+	if p.Symbols != "" {
+		parts = append(parts, fmt.Sprintf("symbols %q", p.Symbols))
+	}
+	if p.Exclude != "" {
+		parts = append(parts, fmt.Sprintf("exclude %q", p.Exclude))
+	}
+	if p.NoAmbiguous {
+		parts = append(parts, "no ambiguous")
+	}
+	return strings.Join(parts, ", ")
+}
+
+func init() {
+	rootCmd.AddCommand(policyCmd)
+	policyCmd.AddCommand(policyListCmd)
+	policyCmd.AddCommand(policyAddCmd)
+	policyCmd.AddCommand(policyRemoveCmd)
+
+	addPasswordFlags(policyAddCmd)
+}
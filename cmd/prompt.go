@@ -0,0 +1,29 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+)
+
+// assumeYes skips confirmation prompts when set by --yes.
+var assumeYes bool
+
+// confirm asks a yes/no question and reports whether the user agreed.
+// It always agrees when --yes was given.
+func confirm(question string) bool {
+	return assumeYes || askYesNo(question)
+}
+
+// askYesNo asks a yes/no question regardless of --yes.
+func askYesNo(question string) bool {
+	var answer string
+	color.New(color.FgYellow).Printf("%s (y/n)?: ", question)
+	fmt.Scanln(&answer)
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
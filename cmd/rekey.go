@@ -0,0 +1,116 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/crypto"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	rekeyKDF         string
+	rekeyMemory      uint32
+	rekeyTime        uint32
+	rekeyParallelism uint8
+	rekeyIterations  uint32
+)
+
+// rekeyCmd represents the rekey command
+var rekeyCmd = &cobra.Command{
+	Use:   "rekey",
+	Short: "Protect the vault with a different key derivation",
+	Long: `Protect the vault with a different key derivation function (KDF) or
+different KDF parameters.
+
+Entries are encrypted with a random vault key, which is stored encrypted
+with a key derived from your master password. Rekeying re-encrypts the
+vault key with the new KDF, so it is quick however many entries the vault
+has. Entries and earlier passwords still encrypted directly with the
+master password are moved to the vault key at the same time.
+
+Every ciphertext records the KDF it was encrypted with, so older vaults
+keep working until they are rekeyed. Vaults written by older versions of
+genp use PBKDF2-SHA256 with 100,000 iterations; Argon2id is much harder to
+crack on GPUs and is used for new vaults by default.
+
+--memory (in MiB), --time and --parallelism tune Argon2id and --iterations
+tunes PBKDF2. The choice is saved in the vault and used again when the
+master password changes. The master password itself stays the same. When
+logged in to GitHub the rekeyed vault is synced.
+
+Examples:
+  genp rekey --kdf argon2id
+  genp rekey --kdf argon2id --memory 256 --time 4
+  genp --vault work rekey`,
+	Run: func(cmd *cobra.Command, args []string) {
+		var params crypto.KDFParams
+		switch rekeyKDF {
+		case crypto.KDFArgon2id, "argon2":
+			params = crypto.DefaultArgon2idParams
+			if cmd.Flags().Changed("memory") {
+				// Checked before converting to KiB, which could overflow
+				if rekeyMemory > crypto.MaxArgon2MemoryMiB {
+					color.Red("Error: --memory must be at most %d MiB, got %d\n", crypto.MaxArgon2MemoryMiB, rekeyMemory)
+					os.Exit(1)
+				}
+				params.Memory = rekeyMemory * 1024
+			}
+			if cmd.Flags().Changed("time") {
+				params.Time = rekeyTime
+			}
+			if cmd.Flags().Changed("parallelism") {
+				params.Parallelism = rekeyParallelism
+			}
+		case crypto.KDFPBKDF2, "pbkdf2":
+			params = crypto.DefaultPBKDF2Params
+			if cmd.Flags().Changed("iterations") {
+				params.Iterations = rekeyIterations
+			}
+		default:
+			color.Red("Error: unknown KDF %q (use argon2id or pbkdf2)\n", rekeyKDF)
+			os.Exit(1)
+		}
+		if err := params.Validate(); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		key, err := store.UnlockVault()
+		if err != nil {
+			color.Red("Error reading master password: %v\n", err)
+			os.Exit(1)
+		}
+		if !confirm("Re-encrypt vault " + store.ActiveVault() + " with " + params.String()) {
+			color.Yellow("Nothing changed.\n")
+			return
+		}
+
+		confPath, count, err := store.RekeyVault(key, params, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Vault %s is now protected with %s\n", store.ActiveVault(), params)
+		if count > 0 {
+			color.Green("[ok] Re-encrypted %d entries still encrypted with the master password\n", count)
+		}
+		syncIfLoggedIn(confPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rekeyCmd)
+
+	rekeyCmd.Flags().StringVar(&rekeyKDF, "kdf", crypto.KDFArgon2id, "Key derivation function: argon2id or pbkdf2")
+	rekeyCmd.Flags().Uint32Var(&rekeyMemory, "memory", crypto.DefaultArgon2idParams.Memory/1024, "Argon2id memory cost in MiB")
+	rekeyCmd.Flags().Uint32Var(&rekeyTime, "time", crypto.DefaultArgon2idParams.Time, "Argon2id number of passes")
+	rekeyCmd.Flags().Uint8Var(&rekeyParallelism, "parallelism", crypto.DefaultArgon2idParams.Parallelism, "Argon2id number of lanes")
+	rekeyCmd.Flags().Uint32Var(&rekeyIterations, "iterations", crypto.DefaultPBKDF2Params.Iterations, "PBKDF2 iteration count")
+	rekeyCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+}
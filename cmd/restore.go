@@ -0,0 +1,68 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var restoreVersion int
+
+// restoreCmd represents the restore command
+var restoreCmd = &cobra.Command{
+	Use:   "restore <name> --version N",
+	Short: "Make an earlier version of an entry current again",
+	Long: `Make an earlier version of a stored entry current again.
+
+Versions are numbered as listed by 'genp history'. The current version is
+moved into the history, so a restore can be undone the same way. You are
+asked for confirmation unless --yes is given. When logged in to GitHub the
+change is synced to the genp-vault repository.
+
+Names are resolved as by 'genp get': a name without folders also finds an
+entry in a folder if it is the only one with that name, and --folder and
+--tag narrow the search down.
+
+Examples:
+  genp restore github --version 1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, entry, err := store.FindEntry(args[0], entryFilter())
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if _, err := entry.Version(restoreVersion); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !confirm("Restore version " + strconv.Itoa(restoreVersion) + " of " + name) {
+			color.Yellow("Nothing restored.\n")
+			return
+		}
+
+		confPath, err := store.RestoreEntry(name, restoreVersion, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Restored version %d of %s\n", restoreVersion, name)
+		syncIfLoggedIn(confPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(restoreCmd)
+
+	restoreCmd.Flags().IntVar(&restoreVersion, "version", 0, "Version to restore, as listed by 'genp history'")
+	restoreCmd.MarkFlagRequired("version")
+	addFilterFlags(restoreCmd)
+	restoreCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+}
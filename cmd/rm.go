@@ -0,0 +1,59 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+// rmCmd represents the rm command
+var rmCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"remove", "delete"},
+	Short:   "Delete a stored entry",
+	Long: `Delete a stored entry from genp.yaml.
+
+You are asked for confirmation unless --yes is given. When logged in to
+GitHub the change is synced to the genp-vault repository.
+
+Names are resolved as by 'genp get': a name without folders also finds an
+entry in a folder if it is the only one with that name, and --folder and
+--tag narrow the search down.
+
+Examples:
+  genp rm github
+  genp rm old-account --yes`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, _, err := store.FindEntry(args[0], entryFilter())
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !confirm("Delete " + name + "? This cannot be undone") {
+			color.Yellow("Nothing deleted.\n")
+			return
+		}
+
+		confPath, err := store.DeleteEntry(name, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Deleted %s\n", name)
+		syncIfLoggedIn(confPath)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(rmCmd)
+
+	addFilterFlags(rmCmd)
+	rmCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+}
@@ -8,16 +8,33 @@ import (
 	"runtime"
 
 	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/store"
 	"github.com/spf13/cobra"
 )
 
+// vaultName is the vault selected with --vault.
+var vaultName string
+
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
 	Use:   "genp",
 	Short: "Generate Password, store and encrypted in CLI",
 	Long: `GenP - Password Generator and Manager
 
-Generate secure passwords and store them with end-to-end encryption.`,
+Generate secure passwords and store them with end-to-end encryption.
+
+--vault (or the GENP_VAULT environment variable) selects a named vault
+created with 'genp vault create'; without it the default vault is used.`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		name := vaultName
+		if !cmd.Flags().Changed("vault") {
+			name = os.Getenv(store.VaultEnv)
+		}
+		if err := store.SelectVault(name); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+	},
 
 	Run: func(cmd *cobra.Command, args []string) {
 		asciiBanner := `
@@ -50,5 +67,6 @@ func Execute() {
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&vaultName, "vault", "", "Vault to use (default: $"+store.VaultEnv+" or the default vault)")
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
 }
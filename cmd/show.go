@@ -4,8 +4,10 @@ Copyright © 2025 @mdxabu
 package cmd
 
 import (
+	"strings"
+	"time"
+
 	"github.com/fatih/color"
-	"github.com/mdxabu/genp/internal/crypto"
 	"github.com/mdxabu/genp/internal/store"
 	"github.com/spf13/cobra"
 )
@@ -17,34 +19,50 @@ var showCmd = &cobra.Command{
 	Long: `Display all stored passwords after decrypting them with your master password.
 
 This command will prompt you for your master password and then display
-all stored passwords in decrypted form.`,
+all stored passwords in decrypted form, together with the username, URLs,
+notes and tags recorded for each of them.
+
+--folder and --tag (repeatable) limit the output to the entries inside a
+folder such as work/aws, or carrying every given tag. Only those entries
+are decrypted.
+
+Examples:
+  genp show
+  genp show --folder work
+  genp show --tag prod`,
 	Run: func(cmd *cobra.Command, args []string) {
-		// Get all encrypted passwords
-		passwords, err := store.GetAllPasswords()
+		// Get all encrypted entries
+		entries, err := store.GetAllEntries()
 		if err != nil {
 			color.Red("Error: %v\n", err)
 			return
 		}
 
+		names := entryFilter().Names(entries)
+		if len(names) == 0 {
+			color.Yellow("No entries match.\n")
+			return
+		}
+
 		// Prompt for master password
-		masterPassword, err := crypto.PromptForMasterPassword("Enter system password: ")
+		key, err := store.UnlockVaultReadOnly()
 		if err != nil {
 			color.Red("Error reading master password: %v\n", err)
 			return
 		}
 
-		// Decrypt and display all passwords
+		// Decrypt and display all entries
 		color.Cyan("\n=== Stored Passwords ===\n")
 		hasError := false
-		for name, encrypted := range passwords {
-			decrypted, err := store.DecryptPassword(encrypted, masterPassword)
+		for _, name := range names {
+			entry := entries[name]
+			details, err := entry.Decrypt(key)
 			if err != nil {
 				color.Red("%s: [Failed to decrypt - incorrect master password or corrupted data]\n", name)
 				hasError = true
 				continue
 			}
-			color.New(color.FgGreen).Printf("%s: ", name)
-			color.Yellow("%s\n", decrypted)
+			printEntry(name, entry, details)
 		}
 
 		if hasError {
@@ -53,6 +71,33 @@ all stored passwords in decrypted form.`,
 	},
 }
 
+// printEntry displays an entry with every non-empty field.
+func printEntry(name string, entry *store.Entry, details store.EntryDetails) {
+	color.New(color.FgGreen).Printf("%s\n", name)
+	printField("Password", details.Password)
+	printField("Username", details.Username)
+	printField("URLs", strings.Join(details.URLs, ", "))
+	printField("Notes", details.Notes)
+	printField("Tags", strings.Join(details.Tags, ", "))
+	if !entry.Created.IsZero() {
+		printField("Created", entry.Created.Local().Format(time.DateTime))
+	}
+	if !entry.Updated.IsZero() {
+		printField("Updated", entry.Updated.Local().Format(time.DateTime))
+	}
+}
+
+// printField displays one labelled field, skipping empty values.
+func printField(label, value string) {
+	if value == "" {
+		return
+	}
+	color.New(color.FgCyan).Printf("  %-9s ", label+":")
+	color.Yellow("%s\n", value)
+}
+
 func init() {
 	rootCmd.AddCommand(showCmd)
+
+	addFilterFlags(showCmd)
 }
@@ -22,6 +22,8 @@ This command will:
   2. Create the genp-vault repo if it doesn't exist
   3. Push your local genp.yaml to the repo
 
+With --vault the selected vault is pushed to its own sync target instead.
+
 You must be logged in first. Use 'genp login' to authenticate.
 
 Examples:
@@ -37,25 +39,34 @@ Examples:
 
 		color.Cyan("Logged in as %s\n", tokenInfo.Username)
 
-		// Ensure vault repo exists
-		color.Cyan("Ensuring genp-vault repository exists...\n")
-		repo, err := github.CreateOrGetVaultRepo(tokenInfo.Token)
+		// Get the local vault file and where it goes
+		confPath, err := store.GetConfigFilePath()
 		if err != nil {
-			color.Red("[error] Failed to set up vault repository: %v\n", err)
+			color.Red("[error] Failed to determine config file path: %v\n", err)
+			return
+		}
+		target, err := store.ActiveSyncTarget()
+		if err != nil {
+			color.Red("[error] %v\n", err)
+			return
+		}
+		if target.Disabled {
+			color.Yellow("Sync is disabled for vault %s.\n", store.ActiveVault())
 			return
 		}
-		color.Green("[ok] Vault repository ready: %s (private: %v)\n", repo.FullName, repo.Private)
 
-		// Get local config path
-		confPath, err := store.GetConfigFilePath()
+		// Ensure vault repo exists
+		color.Cyan("Ensuring vault repository exists...\n")
+		repo, err := github.CreateOrGetRepo(tokenInfo.Token, target.Repo)
 		if err != nil {
-			color.Red("[error] Failed to determine config file path: %v\n", err)
+			color.Red("[error] Failed to set up vault repository: %v\n", err)
 			return
 		}
+		color.Green("[ok] Vault repository ready: %s (private: %v)\n", repo.FullName, repo.Private)
 
 		// Sync the config file
-		color.Cyan("Pushing genp.yaml to vault...\n")
-		if err := github.SyncConfigToVault(confPath); err != nil {
+		color.Cyan("Pushing %s to vault...\n", target.Path)
+		if err := github.SyncConfigToRepo(confPath, target.Repo, target.Path); err != nil {
 			color.Red("[error] Failed to sync: %v\n", err)
 			return
 		}
@@ -64,6 +75,41 @@ Examples:
 	},
 }
 
+// syncIfLoggedIn pushes the active vault to its GitHub sync target after a
+// local change. Failures only warn, since the change is already stored
+// locally.
+func syncIfLoggedIn(confPath string) {
+	if !github.IsLoggedIn() {
+		return
+	}
+	target, err := store.ActiveSyncTarget()
+	if err != nil {
+		color.Yellow("[warn] Failed to sync to GitHub vault: %v\n", err)
+		return
+	}
+	if target.Disabled {
+		return
+	}
+
+	color.Cyan("Syncing to GitHub vault...\n")
+	if err := github.SyncConfigToRepo(confPath, target.Repo, target.Path); err != nil {
+		color.Yellow("[warn] Failed to sync to GitHub vault: %v\n", err)
+		color.Yellow("  Your changes are still stored locally.\n")
+	} else {
+		color.Green("[ok] Synced to GitHub %s\n", syncTargetName(target))
+	}
+}
+
+// syncTargetName returns the repository and file a vault syncs to, such as
+// genp-vault/vaults/work.yaml.
+func syncTargetName(target store.SyncTarget) string {
+	repo := target.Repo
+	if repo == "" {
+		repo = "genp-vault"
+	}
+	return repo + "/" + target.Path
+}
+
 func init() {
 	rootCmd.AddCommand(syncCmd)
 }
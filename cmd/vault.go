@@ -0,0 +1,157 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+package cmd
+
+import (
+	"os"
+	"runtime"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/crypto"
+	"github.com/mdxabu/genp/internal/store"
+	"github.com/spf13/cobra"
+)
+
+var (
+	vaultSyncRepo string
+	vaultSyncPath string
+	vaultNoSync   bool
+)
+
+// vaultCmd represents the vault command
+var vaultCmd = &cobra.Command{
+	Use:   "vault",
+	Short: "Manage named vaults",
+	Long: `Manage named vaults, such as work, personal or per-project vaults.
+
+Every vault is a separate file with its own entries, its own master
+password and its own GitHub sync target. The default vault is genp.yaml
+and is unlocked with your system password until you give it a master
+password with 'genp init'. Select another vault for any
+command with --vault <name> or the GENP_VAULT environment variable.
+
+Examples:
+  genp vault create work
+  genp --vault work create -0 -A -$
+  GENP_VAULT=work genp show
+  genp vault list
+  genp vault delete work`,
+}
+
+// vaultListCmd represents the vault list command
+var vaultListCmd = &cobra.Command{
+	Use:     "list",
+	Aliases: []string{"ls"},
+	Short:   "List vaults",
+	Run: func(cmd *cobra.Command, args []string) {
+		vaults, err := store.ListVaults()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		color.Cyan("=== Vaults ===\n")
+		for _, v := range vaults {
+			marker := " "
+			if v.Name == store.ActiveVault() {
+				marker = "*"
+			}
+			if v.Err != nil {
+				color.New(color.FgGreen).Printf("%s %s: ", marker, v.Name)
+				color.Red("cannot be read: %v\n", v.Err)
+				continue
+			}
+			unlock := "system password"
+			if v.OwnMasterPassword {
+				unlock = "own master password"
+			}
+			if v.Auth != crypto.AuthNone && (v.OwnMasterPassword || v.Auth != crypto.AuthSystem) {
+				unlock += ", verified by " + v.Auth
+			}
+			sync := "sync off"
+			if !v.Sync.Disabled {
+				sync = "syncs to " + syncTargetName(v.Sync)
+			}
+			color.New(color.FgGreen).Printf("%s %s: ", marker, v.Name)
+			color.Yellow("%d entries, %s, %s\n", v.Entries, unlock, sync)
+		}
+	},
+}
+
+// vaultCreateCmd represents the vault create command
+var vaultCreateCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a vault with its own master password",
+	Long: `Create an empty vault protected by its own master password.
+
+The vault is synced to vaults/<name>.yaml in the genp-vault repository
+when you are logged in to GitHub. Use --sync-repo and --sync-path to sync
+it elsewhere, or --no-sync to keep it local.
+
+Examples:
+  genp vault create work
+  genp vault create client-x --sync-repo client-x-vault --sync-path genp.yaml
+  genp vault create scratch --no-sync`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if err := store.ValidateVaultName(name); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		color.Cyan("Choose the master password of vault %s.\n", name)
+		masterPassword, err := promptNewPassword()
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		target := store.SyncTarget{Repo: vaultSyncRepo, Path: vaultSyncPath, Disabled: vaultNoSync}
+		confPath, err := store.CreateVault(name, masterPassword, target, runtime.GOOS)
+		if err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Created vault %s at %s\n", name, confPath)
+		color.Cyan("Use it with --vault %s or %s=%s.\n", name, store.VaultEnv, name)
+	},
+}
+
+// vaultDeleteCmd represents the vault delete command
+var vaultDeleteCmd = &cobra.Command{
+	Use:     "delete <name>",
+	Aliases: []string{"rm"},
+	Short:   "Delete a vault and all of its entries",
+	Long: `Delete a named vault file and its backup. The default vault cannot be
+deleted. Copies already synced to GitHub are left in place.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		name := args[0]
+		if !confirm("Delete vault " + name + " and all of its entries? This cannot be undone") {
+			color.Yellow("Nothing deleted.\n")
+			return
+		}
+
+		if _, err := store.DeleteVault(name, runtime.GOOS); err != nil {
+			color.Red("Error: %v\n", err)
+			os.Exit(1)
+		}
+		color.Green("[ok] Deleted vault %s\n", name)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(vaultCmd)
+	vaultCmd.AddCommand(vaultListCmd)
+	vaultCmd.AddCommand(vaultCreateCmd)
+	vaultCmd.AddCommand(vaultDeleteCmd)
+
+	vaultCreateCmd.Flags().StringVar(&vaultSyncRepo, "sync-repo", "", "GitHub repository to sync the vault to (default: genp-vault)")
+	vaultCreateCmd.Flags().StringVar(&vaultSyncPath, "sync-path", "", "Path of the vault file in the repository (default: vaults/<name>.yaml)")
+	vaultCreateCmd.Flags().BoolVar(&vaultNoSync, "no-sync", false, "Never sync this vault to GitHub")
+	vaultCreateCmd.MarkFlagsMutuallyExclusive("no-sync", "sync-repo")
+	vaultCreateCmd.MarkFlagsMutuallyExclusive("no-sync", "sync-path")
+	vaultDeleteCmd.Flags().BoolVarP(&assumeYes, "yes", "y", false, "Do not ask for confirmation")
+}
@@ -0,0 +1,92 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+
+package clipboard
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var (
+	pbcopy = &commandBackend{name: "pbcopy", copyCmd: []string{"pbcopy"}, pasteCmd: []string{"pbpaste"}}
+	wlCopy = &commandBackend{name: "wl-copy", copyCmd: []string{"wl-copy"}, clearCmd: []string{"wl-copy", "--clear"}, pasteCmd: []string{"wl-paste", "--no-newline"}}
+	xclip  = &commandBackend{name: "xclip", copyCmd: []string{"xclip", "-selection", "clipboard"}, pasteCmd: []string{"xclip", "-selection", "clipboard", "-o"}}
+)
+
+// maxErrorOutput bounds the error text read back from a failed tool.
+const maxErrorOutput = 512
+
+// commandBackend drives an external copy/paste tool.
+type commandBackend struct {
+	name     string
+	copyCmd  []string
+	clearCmd []string // used instead of copying "" when set
+	pasteCmd []string
+}
+
+func (c *commandBackend) Name() string { return c.name }
+
+func (c *commandBackend) Write(text string) error {
+	args := c.copyCmd
+	if text == "" && c.clearCmd != nil {
+		args = c.clearCmd
+	}
+
+	// xclip and wl-copy fork a child that serves the selection and keeps
+	// the inherited stdout and stderr open until it is replaced. Waiting
+	// for a pipe would hang, so stdout is discarded and stderr goes to a
+	// temporary file that is read back, bounded, only if the tool fails.
+	stderr, err := os.CreateTemp("", "genp-clip-*")
+	if err != nil {
+		return fmt.Errorf("%s failed: %w", args[0], err)
+	}
+	defer os.Remove(stderr.Name())
+	defer stderr.Close()
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stderr = stderr
+	if err := cmd.Run(); err != nil {
+		output, _ := io.ReadAll(io.NewSectionReader(stderr, 0, maxErrorOutput))
+		return fmt.Errorf("%s failed: %v %s", args[0], err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (c *commandBackend) Read() (string, error) {
+	output, err := exec.Command(c.pasteCmd[0], c.pasteCmd[1:]...).Output()
+	if err != nil {
+		return "", fmt.Errorf("%s failed: %w", c.pasteCmd[0], err)
+	}
+	return string(output), nil
+}
+
+// OSC52 writes to the clipboard of the local terminal emulator through the
+// OSC 52 escape sequence, which also works over SSH. Terminals do not let
+// programs read the clipboard back, so Read always fails.
+type OSC52 struct {
+	out io.Writer
+}
+
+// NewOSC52 returns an OSC 52 backend that writes escapes to out, which
+// must be connected to the terminal.
+func NewOSC52(out io.Writer) *OSC52 {
+	return &OSC52{out: out}
+}
+
+func (o *OSC52) Name() string { return "osc52" }
+
+func (o *OSC52) Write(text string) error {
+	_, err := fmt.Fprintf(o.out, "\033]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err
+}
+
+func (o *OSC52) Read() (string, error) {
+	return "", ErrReadUnsupported
+}
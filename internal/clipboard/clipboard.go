@@ -0,0 +1,198 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+
+// Package clipboard places secrets on the system clipboard and clears them
+// again. The backend is pluggable so tests can swap in an in-memory fake.
+package clipboard
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// BackendEnv selects a backend by name, overriding detection.
+const BackendEnv = "GENP_CLIPBOARD"
+
+var (
+	// ErrReadUnsupported is returned by backends that can only write,
+	// such as the OSC 52 terminal escape.
+	ErrReadUnsupported = errors.New("clipboard backend cannot read the clipboard")
+	// ErrNoBackend is returned when no usable clipboard backend is found.
+	ErrNoBackend = errors.New("no clipboard backend found (install xclip or wl-clipboard, or set " + BackendEnv + "=osc52)")
+)
+
+// Backend reads and writes a clipboard.
+type Backend interface {
+	// Name identifies the backend, e.g. for BackendEnv.
+	Name() string
+	// Write replaces the clipboard contents; an empty string clears it.
+	Write(text string) error
+	// Read returns the clipboard contents or ErrReadUnsupported.
+	Read() (string, error)
+}
+
+var (
+	mu       sync.Mutex
+	override Backend
+)
+
+// Use makes every later call to Current return b. Passing nil restores
+// automatic detection.
+func Use(b Backend) {
+	mu.Lock()
+	defer mu.Unlock()
+	override = b
+}
+
+// Current returns the backend set with Use, the one named by BackendEnv,
+// or the best backend detected for this system.
+func Current() (Backend, error) {
+	mu.Lock()
+	b := override
+	mu.Unlock()
+	if b != nil {
+		return b, nil
+	}
+
+	if name := os.Getenv(BackendEnv); name != "" {
+		return ByName(name)
+	}
+	return detect()
+}
+
+// ByName returns the backend with the given name.
+func ByName(name string) (Backend, error) {
+	switch name {
+	case "pbcopy":
+		return pbcopy, nil
+	case "wl-copy":
+		return wlCopy, nil
+	case "xclip":
+		return xclip, nil
+	case "osc52":
+		return NewOSC52(os.Stderr), nil
+	default:
+		return nil, fmt.Errorf("unknown clipboard backend %q (use pbcopy, wl-copy, xclip or osc52)", name)
+	}
+}
+
+// detect picks a backend from the platform and session environment.
+// Remote sessions without a display fall back to the OSC 52 escape, which
+// the local terminal emulator turns into a clipboard write.
+func detect() (Backend, error) {
+	if runtime.GOOS == "darwin" {
+		return pbcopy, nil
+	}
+	if os.Getenv("WAYLAND_DISPLAY") != "" && hasCommand("wl-copy") {
+		return wlCopy, nil
+	}
+	if os.Getenv("DISPLAY") != "" && hasCommand("xclip") {
+		return xclip, nil
+	}
+	if os.Getenv("SSH_TTY") != "" || os.Getenv("SSH_CONNECTION") != "" {
+		return NewOSC52(os.Stderr), nil
+	}
+	return nil, ErrNoBackend
+}
+
+func hasCommand(name string) bool {
+	_, err := exec.LookPath(name)
+	return err == nil
+}
+
+// Fingerprint lets a clearing process recognise a secret without holding
+// it: an HMAC-SHA256 of the secret under a random, single-use key, so equal
+// secrets never produce equal fingerprints.
+type Fingerprint struct {
+	key []byte
+	sum []byte
+}
+
+// NewFingerprint returns the fingerprint of text under a fresh random key.
+func NewFingerprint(text string) (Fingerprint, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return Fingerprint{}, fmt.Errorf("failed to generate fingerprint key: %w", err)
+	}
+	return Fingerprint{key: key, sum: fingerprintSum(key, text)}, nil
+}
+
+// ParseFingerprint reads a fingerprint written by Fingerprint.String.
+func ParseFingerprint(s string) (Fingerprint, error) {
+	hexKey, hexSum, ok := strings.Cut(strings.TrimSpace(s), ":")
+	key, keyErr := hex.DecodeString(hexKey)
+	sum, sumErr := hex.DecodeString(hexSum)
+	if !ok || keyErr != nil || sumErr != nil || len(key) != 32 || len(sum) != sha256.Size {
+		return Fingerprint{}, errors.New("invalid clipboard fingerprint")
+	}
+	return Fingerprint{key: key, sum: sum}, nil
+}
+
+// String encodes the fingerprint for handing it to another process.
+func (f Fingerprint) String() string {
+	return hex.EncodeToString(f.key) + ":" + hex.EncodeToString(f.sum)
+}
+
+// Matches reports whether text is the fingerprinted secret.
+func (f Fingerprint) Matches(text string) bool {
+	return len(f.key) != 0 && hmac.Equal(fingerprintSum(f.key, text), f.sum)
+}
+
+func fingerprintSum(key []byte, text string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(text))
+	return mac.Sum(nil)
+}
+
+// ClearIfUnchanged clears the clipboard if it still holds the secret with
+// fingerprint fp, leaving anything copied since then alone. Backends that
+// cannot read are cleared unconditionally, since the secret may still be
+// there. It reports whether the clipboard was cleared.
+func ClearIfUnchanged(b Backend, fp Fingerprint) (bool, error) {
+	current, err := b.Read()
+	if err != nil && !errors.Is(err, ErrReadUnsupported) {
+		return false, fmt.Errorf("failed to read clipboard: %w", err)
+	}
+	if err == nil && !fp.Matches(current) {
+		return false, nil
+	}
+
+	if err := b.Write(""); err != nil {
+		return false, fmt.Errorf("failed to clear clipboard: %w", err)
+	}
+	return true, nil
+}
+
+// Memory is an in-memory clipboard for tests.
+type Memory struct {
+	mu   sync.Mutex
+	text string
+}
+
+// Name implements Backend.
+func (m *Memory) Name() string { return "memory" }
+
+// Write implements Backend.
+func (m *Memory) Write(text string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.text = text
+	return nil
+}
+
+// Read implements Backend.
+func (m *Memory) Read() (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.text, nil
+}
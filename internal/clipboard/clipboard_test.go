@@ -0,0 +1,141 @@
+/*
+Copyright © 2026 @mdxabu
+*/
+
+package clipboard
+
+import (
+	"bytes"
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func mustFingerprint(t *testing.T, text string) Fingerprint {
+	t.Helper()
+	fp, err := NewFingerprint(text)
+	if err != nil {
+		t.Fatalf("NewFingerprint failed: %v", err)
+	}
+	return fp
+}
+
+func TestClearIfUnchangedClearsOurSecret(t *testing.T) {
+	mem := &Memory{}
+	mem.Write("s3cret")
+
+	cleared, err := ClearIfUnchanged(mem, mustFingerprint(t, "s3cret"))
+	if err != nil {
+		t.Fatalf("ClearIfUnchanged failed: %v", err)
+	}
+	if !cleared {
+		t.Fatal("Expected the clipboard to be cleared")
+	}
+	if text, _ := mem.Read(); text != "" {
+		t.Fatalf("Expected empty clipboard, got %q", text)
+	}
+}
+
+func TestClearIfUnchangedKeepsNewerContent(t *testing.T) {
+	mem := &Memory{}
+	mem.Write("s3cret")
+	mem.Write("something the user copied later")
+
+	cleared, err := ClearIfUnchanged(mem, mustFingerprint(t, "s3cret"))
+	if err != nil {
+		t.Fatalf("ClearIfUnchanged failed: %v", err)
+	}
+	if cleared {
+		t.Fatal("Clipboard was cleared although it no longer held the secret")
+	}
+	if text, _ := mem.Read(); text != "something the user copied later" {
+		t.Fatalf("Clipboard content changed to %q", text)
+	}
+}
+
+func TestClearIfUnchangedWriteOnlyBackend(t *testing.T) {
+	var out bytes.Buffer
+	osc := NewOSC52(&out)
+
+	if _, err := osc.Read(); !errors.Is(err, ErrReadUnsupported) {
+		t.Fatalf("Expected ErrReadUnsupported, got: %v", err)
+	}
+	cleared, err := ClearIfUnchanged(osc, mustFingerprint(t, "s3cret"))
+	if err != nil {
+		t.Fatalf("ClearIfUnchanged failed: %v", err)
+	}
+	if !cleared || out.String() != "\033]52;c;\a" {
+		t.Fatalf("Expected an OSC 52 clear sequence, got %q", out.String())
+	}
+}
+
+func TestOSC52Write(t *testing.T) {
+	var out bytes.Buffer
+	if err := NewOSC52(&out).Write("hi"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if got := out.String(); got != "\033]52;c;aGk=\a" {
+		t.Fatalf("Unexpected escape sequence %q", got)
+	}
+}
+
+func TestUseOverridesDetection(t *testing.T) {
+	mem := &Memory{}
+	Use(mem)
+	defer Use(nil)
+
+	b, err := Current()
+	if err != nil {
+		t.Fatalf("Current failed: %v", err)
+	}
+	if b != mem {
+		t.Fatalf("Expected the memory backend, got %s", b.Name())
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	fp := mustFingerprint(t, "s3cret")
+	if !fp.Matches("s3cret") || fp.Matches("s3cret ") {
+		t.Fatal("Fingerprint does not recognise exactly its secret")
+	}
+	if other := mustFingerprint(t, "s3cret"); other.String() == fp.String() {
+		t.Fatal("Equal secrets produced equal fingerprints")
+	}
+
+	parsed, err := ParseFingerprint(fp.String() + "\n")
+	if err != nil || !parsed.Matches("s3cret") {
+		t.Fatalf("ParseFingerprint round trip failed: %v", err)
+	}
+	for _, bad := range []string{"", "abcd", strings.Repeat("0", 64) + ":zz"} {
+		if _, err := ParseFingerprint(bad); err == nil {
+			t.Errorf("ParseFingerprint(%q) should fail", bad)
+		}
+	}
+	if (Fingerprint{}).Matches("") {
+		t.Fatal("The zero fingerprint must not match anything")
+	}
+}
+
+func TestCommandBackendDoesNotWaitForForkedChild(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("needs a POSIX shell")
+	}
+	// Like xclip, the tool leaves a child behind that holds stdout and
+	// stderr open
+	tool := &commandBackend{name: "fork", copyCmd: []string{"sh", "-c", "cat >/dev/null; sleep 5 &"}}
+
+	start := time.Now()
+	if err := tool.Write("s3cret"); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 3*time.Second {
+		t.Fatalf("Write waited %s for the forked child", elapsed)
+	}
+
+	failing := &commandBackend{name: "fail", copyCmd: []string{"sh", "-c", "echo no display >&2; exit 1"}}
+	if err := failing.Write("s3cret"); err == nil || !strings.Contains(err.Error(), "no display") {
+		t.Fatalf("Expected the tool's error output, got %v", err)
+	}
+}
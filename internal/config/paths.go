@@ -17,6 +17,10 @@ const (
 	ConfigFileName = "genp.yaml"
 	// GitHubTokenFileName is the name of the GitHub token file
 	GitHubTokenFileName = "github_token"
+	// VaultsDirName is the directory holding named vaults, next to genp.yaml
+	VaultsDirName = "vaults"
+	// VaultFileExt is the file extension of a named vault
+	VaultFileExt = ".yaml"
 )
 
 // BaseDir determines the per-OS base config directory.
@@ -111,3 +115,21 @@ func GitHubTokenPath(osName string) (string, error) {
 	}
 	return filepath.Join(baseDir, GitHubTokenFileName), nil
 }
+
+// VaultsDir returns the directory holding the named vaults for the given OS
+func VaultsDir(osName string) (string, error) {
+	baseDir, err := BaseDir(osName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(baseDir, VaultsDirName), nil
+}
+
+// VaultFilePath returns the full path to the file of a named vault
+func VaultFilePath(osName string, vaultName string) (string, error) {
+	vaultsDir, err := VaultsDir(osName)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(vaultsDir, vaultName+VaultFileExt), nil
+}
@@ -0,0 +1,268 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// Names of the authenticators, as used in the auth settings of a vault.
+const (
+	AuthSystem  = "system"
+	AuthSudo    = "sudo"
+	AuthPAM     = "pam"
+	AuthKeyfile = "keyfile"
+	AuthNone    = "none"
+)
+
+// Defaults for the PAM authenticator.
+const (
+	DefaultPAMHelper  = "pamtester"
+	DefaultPAMService = "login"
+)
+
+// Authenticator verifies the user before a vault is unlocked. It only
+// checks the user; it never provides an encryption key.
+type Authenticator interface {
+	// Name returns the name of the authenticator, e.g. AuthSudo.
+	Name() string
+	// NeedsPassword reports whether Verify needs the system password.
+	NeedsPassword() bool
+	// Verify checks the user. password is the system password if
+	// NeedsPassword is true and empty otherwise.
+	Verify(password string) error
+}
+
+// AuthConfig selects an authenticator and holds its settings.
+type AuthConfig struct {
+	Method string `yaml:"method"`
+	// PAMService is the PAM service to authenticate against.
+	PAMService string `yaml:"pam_service,omitempty"`
+	// PAMHelper is the helper program run as
+	// <helper> <service> <user> authenticate, reading the password from stdin.
+	// It must be DefaultPAMHelper or an absolute path, see checkPAMHelper.
+	PAMHelper string `yaml:"pam_helper,omitempty"`
+	// Keyfile is the path of the file whose possession is checked.
+	Keyfile string `yaml:"keyfile,omitempty"`
+	// KeyfileSHA256 is the hex SHA-256 digest the key file must have.
+	KeyfileSHA256 string `yaml:"keyfile_sha256,omitempty"`
+}
+
+// NewAuthenticator returns the authenticator selected by cfg. An empty
+// method selects the system authenticator.
+func NewAuthenticator(cfg AuthConfig) (Authenticator, error) {
+	switch cfg.Method {
+	case "", AuthSystem:
+		return SystemAuthenticator(), nil
+	case AuthSudo:
+		return SudoAuthenticator{}, nil
+	case AuthPAM:
+		a := PAMAuthenticator{Service: cfg.PAMService, Helper: cfg.PAMHelper}
+		if a.Service == "" {
+			a.Service = DefaultPAMService
+		}
+		if a.Helper == "" {
+			a.Helper = DefaultPAMHelper
+		}
+		if !pamServicePattern.MatchString(a.Service) {
+			return nil, fmt.Errorf("invalid PAM service %q", a.Service)
+		}
+		if err := checkPAMHelper(a.Helper); err != nil {
+			return nil, err
+		}
+		return a, nil
+	case AuthKeyfile:
+		if cfg.Keyfile == "" || cfg.KeyfileSHA256 == "" {
+			return nil, errors.New("the keyfile authenticator needs keyfile and keyfile_sha256")
+		}
+		return KeyfileAuthenticator{Path: cfg.Keyfile, SHA256: cfg.KeyfileSHA256}, nil
+	case AuthNone:
+		return NoAuthenticator{}, nil
+	default:
+		return nil, fmt.Errorf("unknown authenticator %q (use %s, %s, %s, %s or %s)", cfg.Method, AuthSystem, AuthSudo, AuthPAM, AuthKeyfile, AuthNone)
+	}
+}
+
+// pamServicePattern matches the names of the service files in /etc/pam.d.
+var pamServicePattern = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+
+// checkPAMHelper refuses PAM helpers that might not be trustworthy. The
+// helper receives the system password and its name is read from the vault
+// file, which may be synced, so it must be DefaultPAMHelper, found in PATH,
+// or an absolute path to a file that only root can change.
+func checkPAMHelper(helper string) error {
+	if helper == DefaultPAMHelper {
+		return nil
+	}
+	if !filepath.IsAbs(helper) {
+		return fmt.Errorf("PAM helper %q must be %s or an absolute path", helper, DefaultPAMHelper)
+	}
+	info, err := os.Stat(helper)
+	if err != nil {
+		return fmt.Errorf("failed to check PAM helper: %w", err)
+	}
+	if !info.Mode().IsRegular() || !ownedByRoot(info) || info.Mode().Perm()&0o022 != 0 {
+		return fmt.Errorf("PAM helper %s must be a file owned by root and writable only by root", helper)
+	}
+	return nil
+}
+
+// SystemAuthenticator returns the authenticator that checks the system
+// password the native way of the current operating system: sudo on Linux,
+// dscl on macOS and the local account database on Windows.
+func SystemAuthenticator() Authenticator {
+	switch runtime.GOOS {
+	case "darwin":
+		return DSCLAuthenticator{}
+	case "windows":
+		return WindowsAuthenticator{}
+	default:
+		return SudoAuthenticator{}
+	}
+}
+
+// SudoAuthenticator checks the system password with sudo.
+type SudoAuthenticator struct{}
+
+// Name returns AuthSudo.
+func (SudoAuthenticator) Name() string { return AuthSudo }
+
+// NeedsPassword returns true.
+func (SudoAuthenticator) NeedsPassword() bool { return true }
+
+// Verify pipes the password into `sudo -k -S -v`, which validates it
+// without running a command.
+// -k invalidates the cached credentials first so it always prompts.
+// -S reads the password from stdin.
+// -v updates the cached credentials (validates) without running a command.
+func (SudoAuthenticator) Verify(password string) error {
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+
+	cmd := exec.Command("sudo", "-k", "-S", "-v")
+	cmd.Stdin = strings.NewReader(password + "\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		outStr := strings.TrimSpace(string(output))
+		if strings.Contains(outStr, "incorrect password") || strings.Contains(outStr, "Sorry") {
+			return fmt.Errorf("system password verification failed: incorrect password")
+		}
+		if outStr != "" {
+			return fmt.Errorf("system password verification failed: %s", outStr)
+		}
+		return fmt.Errorf("system password verification failed: incorrect password")
+	}
+
+	// Immediately invalidate the sudo timestamp so we don't leave an open sudo session
+	_ = exec.Command("sudo", "-k").Run()
+
+	return nil
+}
+
+// PAMAuthenticator checks the system password against a PAM service
+// through a helper program such as pamtester, so genp itself does not
+// need cgo or root.
+type PAMAuthenticator struct {
+	Service string
+	Helper  string
+}
+
+// Name returns AuthPAM.
+func (PAMAuthenticator) Name() string { return AuthPAM }
+
+// NeedsPassword returns true.
+func (PAMAuthenticator) NeedsPassword() bool { return true }
+
+// Verify runs <helper> <service> <user> authenticate with the password on
+// stdin. The helper must exit 0 only if authentication succeeded.
+func (a PAMAuthenticator) Verify(password string) error {
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
+	}
+	currentUser, err := user.Current()
+	if err != nil {
+		return fmt.Errorf("failed to determine current user: %w", err)
+	}
+
+	cmd := exec.Command(a.Helper, a.Service, currentUser.Username, "authenticate")
+	cmd.Stdin = strings.NewReader(password + "\n")
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			return fmt.Errorf("failed to run PAM helper %s: %w", a.Helper, err)
+		}
+		if outStr := strings.TrimSpace(string(output)); outStr != "" {
+			return fmt.Errorf("PAM authentication failed: %s", outStr)
+		}
+		return fmt.Errorf("PAM authentication failed: incorrect password")
+	}
+	return nil
+}
+
+// KeyfileAuthenticator checks that a key file, e.g. on a USB stick, is
+// present and unchanged.
+type KeyfileAuthenticator struct {
+	Path string
+	// SHA256 is the hex digest the file must have, see KeyfileDigest.
+	SHA256 string
+}
+
+// Name returns AuthKeyfile.
+func (KeyfileAuthenticator) Name() string { return AuthKeyfile }
+
+// NeedsPassword returns false.
+func (KeyfileAuthenticator) NeedsPassword() bool { return false }
+
+// Verify compares the digest of the key file with the expected one.
+func (a KeyfileAuthenticator) Verify(string) error {
+	digest, err := KeyfileDigest(a.Path)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(digest), []byte(strings.ToLower(a.SHA256))) != 1 {
+		return fmt.Errorf("key file %s does not match", a.Path)
+	}
+	return nil
+}
+
+// KeyfileDigest returns the hex SHA-256 digest of the file at path.
+func KeyfileDigest(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read key file: %w", err)
+	}
+	if len(data) == 0 {
+		return "", fmt.Errorf("key file %s is empty", path)
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// NoAuthenticator accepts everyone. The vault is still protected by its
+// master password.
+type NoAuthenticator struct{}
+
+// Name returns AuthNone.
+func (NoAuthenticator) Name() string { return AuthNone }
+
+// NeedsPassword returns false.
+func (NoAuthenticator) NeedsPassword() bool { return false }
+
+// Verify always succeeds.
+func (NoAuthenticator) Verify(string) error { return nil }
@@ -0,0 +1,128 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestNewAuthenticator(t *testing.T) {
+	tests := []struct {
+		cfg  AuthConfig
+		want string
+	}{
+		{AuthConfig{}, SystemAuthenticator().Name()},
+		{AuthConfig{Method: AuthSudo}, AuthSudo},
+		{AuthConfig{Method: AuthPAM}, AuthPAM},
+		{AuthConfig{Method: AuthKeyfile, Keyfile: "/k", KeyfileSHA256: "00"}, AuthKeyfile},
+		{AuthConfig{Method: AuthNone}, AuthNone},
+	}
+	for _, tt := range tests {
+		auth, err := NewAuthenticator(tt.cfg)
+		if err != nil || auth.Name() != tt.want {
+			t.Errorf("NewAuthenticator(%+v) = %v, %v; want %s", tt.cfg, auth, err, tt.want)
+		}
+	}
+
+	pam, _ := NewAuthenticator(AuthConfig{Method: AuthPAM})
+	if p := pam.(PAMAuthenticator); p.Service != DefaultPAMService || p.Helper != DefaultPAMHelper {
+		t.Errorf("PAM defaults not applied: %+v", p)
+	}
+	for _, cfg := range []AuthConfig{{Method: "ldap"}, {Method: AuthKeyfile}, {Method: AuthPAM, PAMService: "../shadow"}} {
+		if _, err := NewAuthenticator(cfg); err == nil {
+			t.Errorf("NewAuthenticator(%+v) should fail", cfg)
+		}
+	}
+}
+
+func TestNewAuthenticatorChecksPAMHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("only the default helper is accepted on Windows")
+	}
+	helper := filepath.Join(t.TempDir(), "pamhelper")
+	if err := os.WriteFile(helper, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatalf("Failed to write helper: %v", err)
+	}
+
+	// Helpers are only trusted if nobody but root can replace them
+	tests := []struct {
+		helper string
+		ok     bool
+	}{
+		{"/bin/sh", true},
+		{"sh", false},
+		{"./pamhelper", false},
+		{helper, os.Geteuid() == 0},
+		{filepath.Join(t.TempDir(), "missing"), false},
+	}
+	for _, tt := range tests {
+		_, err := NewAuthenticator(AuthConfig{Method: AuthPAM, PAMHelper: tt.helper})
+		if (err == nil) != tt.ok {
+			t.Errorf("NewAuthenticator with helper %s: err = %v, want ok = %v", tt.helper, err, tt.ok)
+		}
+	}
+
+	os.Chmod(helper, 0o777)
+	if _, err := NewAuthenticator(AuthConfig{Method: AuthPAM, PAMHelper: helper}); err == nil {
+		t.Error("NewAuthenticator accepted a world-writable helper")
+	}
+}
+
+func TestKeyfileAuthenticator(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "genp.key")
+	if err := os.WriteFile(path, []byte("random key material"), 0o600); err != nil {
+		t.Fatalf("Failed to write key file: %v", err)
+	}
+	digest, err := KeyfileDigest(path)
+	if err != nil {
+		t.Fatalf("KeyfileDigest failed: %v", err)
+	}
+
+	auth := KeyfileAuthenticator{Path: path, SHA256: strings.ToUpper(digest)}
+	if auth.NeedsPassword() {
+		t.Fatal("The keyfile authenticator should not need a password")
+	}
+	if err := auth.Verify(""); err != nil {
+		t.Fatalf("Verify with the key file failed: %v", err)
+	}
+
+	os.WriteFile(path, []byte("other key material"), 0o600)
+	if err := auth.Verify(""); err == nil {
+		t.Fatal("Verify accepted a changed key file")
+	}
+	os.Remove(path)
+	if err := auth.Verify(""); err == nil {
+		t.Fatal("Verify accepted a missing key file")
+	}
+}
+
+func TestPAMAuthenticatorRunsHelper(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake helper is a shell script")
+	}
+	// The fake helper accepts "letmein" for the genp service only
+	helper := filepath.Join(t.TempDir(), "pamhelper")
+	script := "#!/bin/sh\n[ \"$1\" = genp ] && [ \"$3\" = authenticate ] || exit 2\nread pw\n[ \"$pw\" = letmein ] || { echo 'Authentication failure'; exit 1; }\n"
+	if err := os.WriteFile(helper, []byte(script), 0o700); err != nil {
+		t.Fatalf("Failed to write helper: %v", err)
+	}
+
+	auth := PAMAuthenticator{Service: "genp", Helper: helper}
+	if err := auth.Verify("letmein"); err != nil {
+		t.Fatalf("Verify with the right password failed: %v", err)
+	}
+	if err := auth.Verify("wrong"); err == nil || !strings.Contains(err.Error(), "Authentication failure") {
+		t.Fatalf("Expected the helper's failure, got %v", err)
+	}
+	missing := PAMAuthenticator{Service: "genp", Helper: filepath.Join(t.TempDir(), "missing")}
+	if err := missing.Verify("letmein"); err == nil || !strings.Contains(err.Error(), "failed to run PAM helper") {
+		t.Fatalf("Expected a missing helper error, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+// Package cryptotest provides fakes of the crypto package's authenticators
+// and password prompts, so the unlock flows can be tested without a
+// terminal or root.
+package cryptotest
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+// FakeAuthenticator is a crypto.Authenticator for tests. It accepts Password, or
+// anyone if Password is empty, and counts how often it was asked.
+type FakeAuthenticator struct {
+	Password string
+	Calls    int
+}
+
+// Name returns "fake".
+func (f *FakeAuthenticator) Name() string { return "fake" }
+
+// NeedsPassword reports whether a password was set.
+func (f *FakeAuthenticator) NeedsPassword() bool { return f.Password != "" }
+
+// Verify compares password with the expected one.
+func (f *FakeAuthenticator) Verify(password string) error {
+	f.Calls++
+	if password != f.Password {
+		return errors.New("fake authentication failed: incorrect password")
+	}
+	return nil
+}
+
+// FakePasswordReader returns a crypto.PasswordReader for tests that answers the
+// prompts with answers, in order, and fails once they run out. Every prompt
+// shown is appended to prompts if it is not nil.
+func FakePasswordReader(prompts *[]string, answers ...string) crypto.PasswordReader {
+	return func(prompt string) (string, error) {
+		if prompts != nil {
+			*prompts = append(*prompts, prompt)
+		}
+		if len(answers) == 0 {
+			return "", fmt.Errorf("unexpected password prompt %q", prompt)
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+}
@@ -0,0 +1,43 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package cryptotest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+func TestPromptAndVerify(t *testing.T) {
+	var prompts []string
+	defer crypto.SetPasswordReader(FakePasswordReader(&prompts, "  login  ", "wrong"))()
+
+	auth := &FakeAuthenticator{Password: "login"}
+	password, err := crypto.PromptAndVerify("System: ", auth)
+	if err != nil || password != "login" {
+		t.Fatalf("PromptAndVerify = %q, %v", password, err)
+	}
+	if _, err := crypto.PromptAndVerify("System: ", auth); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("Expected authentication failure, got %v", err)
+	}
+	if auth.Calls != 2 || len(prompts) != 2 || prompts[0] != "System: " {
+		t.Fatalf("Unexpected calls %d and prompts %q", auth.Calls, prompts)
+	}
+
+	// Authenticators without a password do not prompt
+	if _, err := crypto.PromptAndVerify("System: ", crypto.NoAuthenticator{}); err != nil || len(prompts) != 2 {
+		t.Fatalf("PromptAndVerify without a password = %v, prompts %q", err, prompts)
+	}
+}
+
+func TestPromptForPasswordRejectsBlankInput(t *testing.T) {
+	defer crypto.SetPasswordReader(FakePasswordReader(nil, "   "))()
+
+	if _, err := crypto.PromptForPassword("Password: "); err == nil || !strings.Contains(err.Error(), "password cannot be empty") {
+		t.Fatalf("Expected 'password cannot be empty', got %v", err)
+	}
+}
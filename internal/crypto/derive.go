@@ -0,0 +1,73 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+)
+
+const (
+	// Argon2id parameters for site derivation. They are part of the
+	// derivation scheme: changing them changes every derived password.
+	deriveTime    = 3
+	deriveMemory  = 64 * 1024 // KiB
+	deriveThreads = 4
+	// deriveSaltPrefix separates derived site keys from any other use of
+	// the master secret.
+	deriveSaltPrefix = "genp-derive-v1"
+)
+
+// DeriveSiteKey derives a reproducible key for a site and login from the
+// master secret using Argon2id. The site is matched case-insensitively,
+// and the counter allows rotating a password without changing the secret.
+func DeriveSiteKey(masterSecret, site, login string, counter uint32) ([]byte, error) {
+	if masterSecret == "" {
+		return nil, errors.New("master secret cannot be empty")
+	}
+	site = strings.ToLower(strings.TrimSpace(site))
+	if site == "" {
+		return nil, errors.New("site cannot be empty")
+	}
+	login = strings.TrimSpace(login)
+
+	// Length-prefix every field so "ab"+"c" never collides with "a"+"bc"
+	salt := []byte(deriveSaltPrefix)
+	for _, field := range []string{site, login} {
+		salt = binary.BigEndian.AppendUint32(salt, uint32(len(field)))
+		salt = append(salt, field...)
+	}
+	salt = binary.BigEndian.AppendUint32(salt, counter)
+
+	return argon2.IDKey([]byte(masterSecret), salt, deriveTime, deriveMemory, deriveThreads, KeySize), nil
+}
+
+// NewDeterministicReader returns an endless stream of pseudo-random bytes
+// determined by key (the AES-256-CTR keystream with a zero IV). It is meant
+// to drive the password generator from a derived site key.
+func NewDeterministicReader(key []byte) (io.Reader, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	stream := cipher.NewCTR(block, make([]byte, aes.BlockSize))
+	return cipher.StreamReader{S: stream, R: zeroReader{}}, nil
+}
+
+// zeroReader yields an endless run of zero bytes.
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -9,13 +9,10 @@ import (
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/rand"
-	"crypto/sha256"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
-
-	"golang.org/x/crypto/pbkdf2"
 )
 
 const (
@@ -25,19 +22,29 @@ const (
 	NonceSize = 12
 	// KeySize is the size of the encryption key in bytes (256 bits)
 	KeySize = 32
-	// Iterations for PBKDF2
+	// Iterations for PBKDF2 in ciphertexts that do not record their KDF
 	Iterations = 100000
 )
 
-// Encrypt encrypts the plaintext using AES-256-GCM with a password-derived key.
-// The output is base64 encoded and contains: salt + nonce + ciphertext
+// Encrypt encrypts the plaintext using AES-256-GCM with a password-derived key,
+// using the KDF chosen with SetDefaultKDF (Argon2id by default).
+// The output is the base64 encoding of a versioned envelope recording the
+// cipher, the KDF and its parameters, the salt and the nonce; see envelope.go.
 func Encrypt(plaintext string, password string) (string, error) {
+	return EncryptWithKDF(plaintext, password, defaultKDF)
+}
+
+// EncryptWithKDF is Encrypt with explicit KDF parameters.
+func EncryptWithKDF(plaintext string, password string, params KDFParams) (string, error) {
 	if plaintext == "" {
 		return "", errors.New("plaintext cannot be empty")
 	}
 	if password == "" {
 		return "", errors.New("password cannot be empty")
 	}
+	if err := params.Validate(); err != nil {
+		return "", err
+	}
 
 	// Generate a random salt
 	salt := make([]byte, SaltSize)
@@ -45,40 +52,40 @@ func Encrypt(plaintext string, password string) (string, error) {
 		return "", fmt.Errorf("failed to generate salt: %w", err)
 	}
 
-	// Derive key from password using PBKDF2
-	key := pbkdf2.Key([]byte(password), salt, Iterations, KeySize, sha256.New)
-
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
-	}
-
 	// Generate nonce
 	nonce := make([]byte, NonceSize)
 	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
 		return "", fmt.Errorf("failed to generate nonce: %w", err)
 	}
 
-	// Encrypt the plaintext
-	ciphertext := gcm.Seal(nil, nonce, []byte(plaintext), nil)
+	header, err := envelopeHeader{
+		version: EnvelopeVersion,
+		cipher:  cipherAES256GCM,
+		kdf:     params,
+		salt:    salt,
+		nonce:   nonce,
+	}.marshal()
+	if err != nil {
+		return "", err
+	}
+
+	// Derive key from password
+	gcm, err := newGCM(params.deriveKey(password, salt))
+	if err != nil {
+		return "", err
+	}
 
-	// Combine salt + nonce + ciphertext
-	result := append(salt, nonce...)
-	result = append(result, ciphertext...)
+	// Encrypt the plaintext, authenticating the header
+	ciphertext := gcm.Seal(header, nonce, []byte(plaintext), header)
 
 	// Encode to base64
-	return base64.StdEncoding.EncodeToString(result), nil
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
 
-// Decrypt decrypts the base64-encoded ciphertext using AES-256-GCM with a password-derived key.
-// The input should contain: salt + nonce + ciphertext
+// Decrypt decrypts ciphertext produced by Encrypt using AES-256-GCM with a
+// password-derived key. The cipher and KDF are read from the envelope;
+// ciphertexts written by older versions of genp are recognized by their
+// format (see envelope.go).
 func Decrypt(encryptedData string, password string) (string, error) {
 	if encryptedData == "" {
 		return "", errors.New("encrypted data cannot be empty")
@@ -93,6 +100,53 @@ func Decrypt(encryptedData string, password string) (string, error) {
 		return "", fmt.Errorf("failed to decode base64: %w", err)
 	}
 
+	if !hasEnvelopeMagic(data) {
+		return decryptHeaderless(data, password, LegacyPBKDF2Params)
+	}
+	plaintext, err := decryptEnvelope(data, password)
+	if err != nil {
+		// A headerless ciphertext whose random salt happens to start with
+		// the magic is not an envelope; it is only that if it decrypts.
+		if legacy, legacyErr := decryptHeaderless(data, password, LegacyPBKDF2Params); legacyErr == nil {
+			return legacy, nil
+		}
+		return "", err
+	}
+	return plaintext, nil
+}
+
+// decryptEnvelope decrypts a decoded envelope.
+func decryptEnvelope(data []byte, password string) (string, error) {
+	header, aad, ciphertext, err := parseEnvelope(data)
+	if err != nil {
+		return "", err
+	}
+	if header.kdf.Algorithm == KDFNone {
+		return "", errors.New("data is encrypted with a key, not a password")
+	}
+	return openEnvelope(header, aad, ciphertext, header.kdf.deriveKey(password, header.salt))
+}
+
+// openEnvelope decrypts the ciphertext of a parsed envelope with key.
+func openEnvelope(header envelopeHeader, aad []byte, ciphertext []byte, key []byte) (string, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	if len(header.nonce) != gcm.NonceSize() || len(ciphertext) < gcm.Overhead() {
+		return "", errors.New("encrypted data is too short")
+	}
+
+	plaintext, err := gcm.Open(nil, header.nonce, ciphertext, aad)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt: incorrect password or corrupted data")
+	}
+	return string(plaintext), nil
+}
+
+// decryptHeaderless decrypts a decoded payload of salt + nonce + ciphertext
+// whose KDF is known from elsewhere.
+func decryptHeaderless(data []byte, password string, params KDFParams) (string, error) {
 	// Check minimum size: salt + nonce + at least some ciphertext
 	minSize := SaltSize + NonceSize + 16 // 16 is the GCM tag size
 	if len(data) < minSize {
@@ -104,19 +158,10 @@ func Decrypt(encryptedData string, password string) (string, error) {
 	nonce := data[SaltSize : SaltSize+NonceSize]
 	ciphertext := data[SaltSize+NonceSize:]
 
-	// Derive key from password using PBKDF2
-	key := pbkdf2.Key([]byte(password), salt, Iterations, KeySize, sha256.New)
-
-	// Create AES cipher
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	// Create GCM mode
-	gcm, err := cipher.NewGCM(block)
+	// Derive key from password
+	gcm, err := newGCM(params.deriveKey(password, salt))
 	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
+		return "", err
 	}
 
 	// Decrypt the ciphertext
@@ -127,3 +172,19 @@ func Decrypt(encryptedData string, password string) (string, error) {
 
 	return string(plaintext), nil
 }
+
+// newGCM creates an AES-256-GCM cipher for key.
+func newGCM(key []byte) (cipher.AEAD, error) {
+	// Create AES cipher
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	// Create GCM mode
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
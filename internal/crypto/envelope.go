@@ -0,0 +1,150 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// Ciphertexts produced by Encrypt are base64-encoded envelopes that
+// describe how to decrypt them:
+//
+//	magic      4 bytes  "GENP"
+//	version    1 byte   EnvelopeVersion
+//	cipher     1 byte   cipherAES256GCM
+//	kdf        1 byte   kdfIDNone, kdfIDPBKDF2 or kdfIDArgon2id
+//	params     1 byte length, then the KDF parameters, big endian:
+//	           none: empty, the key is given directly
+//	           PBKDF2: iterations (4 bytes)
+//	           Argon2id: memory in KiB (4), time (4), parallelism (1)
+//	salt       1 byte length, then the salt (empty without a KDF)
+//	nonce      1 byte length, then the nonce
+//	ciphertext the rest, including the GCM tag
+//
+// Everything before the ciphertext is the header. It is authenticated as
+// additional data, so tampering with the parameters fails decryption.
+//
+// Ciphertexts written before genp recorded the KDF are headerless:
+// base64(salt + nonce + ciphertext), with PBKDF2-SHA256 and Iterations.
+// They are still decrypted.
+
+const (
+	// EnvelopeVersion is the envelope format written by Encrypt.
+	EnvelopeVersion = 1
+
+	envelopeMagic   = "GENP"
+	cipherAES256GCM = 1
+	kdfIDNone       = 0
+	kdfIDPBKDF2     = 1
+	kdfIDArgon2id   = 2
+)
+
+// envelopeHeader is the decoded header of an envelope.
+type envelopeHeader struct {
+	version uint8
+	cipher  uint8
+	kdf     KDFParams
+	salt    []byte
+	nonce   []byte
+}
+
+// hasEnvelopeMagic reports whether data starts like an envelope.
+func hasEnvelopeMagic(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(envelopeMagic))
+}
+
+// marshal encodes the header in the layout described above.
+func (h envelopeHeader) marshal() ([]byte, error) {
+	var params []byte
+	var kdfID byte
+	switch h.kdf.Algorithm {
+	case KDFNone:
+		kdfID = kdfIDNone
+	case KDFPBKDF2:
+		kdfID = kdfIDPBKDF2
+		params = binary.BigEndian.AppendUint32(params, h.kdf.Iterations)
+	case KDFArgon2id:
+		kdfID = kdfIDArgon2id
+		params = binary.BigEndian.AppendUint32(params, h.kdf.Memory)
+		params = binary.BigEndian.AppendUint32(params, h.kdf.Time)
+		params = append(params, h.kdf.Parallelism)
+	default:
+		return nil, fmt.Errorf("unknown KDF %q", h.kdf.Algorithm)
+	}
+
+	buf := []byte(envelopeMagic)
+	buf = append(buf, h.version, h.cipher, kdfID)
+	for _, field := range [][]byte{params, h.salt, h.nonce} {
+		if len(field) > 255 {
+			return nil, errors.New("envelope field is too long")
+		}
+		buf = append(buf, byte(len(field)))
+		buf = append(buf, field...)
+	}
+	return buf, nil
+}
+
+// parseEnvelope splits an envelope into its header, the raw header bytes
+// used as additional data, and the ciphertext.
+func parseEnvelope(data []byte) (envelopeHeader, []byte, []byte, error) {
+	var h envelopeHeader
+	if !hasEnvelopeMagic(data) {
+		return h, nil, nil, errors.New("missing envelope magic")
+	}
+	rest := data[len(envelopeMagic):]
+	if len(rest) < 3 {
+		return h, nil, nil, errors.New("encrypted data is too short")
+	}
+	h.version, h.cipher = rest[0], rest[1]
+	kdfID := rest[2]
+	rest = rest[3:]
+
+	if h.version != EnvelopeVersion {
+		return h, nil, nil, fmt.Errorf("unsupported envelope version %d", h.version)
+	}
+	if h.cipher != cipherAES256GCM {
+		return h, nil, nil, fmt.Errorf("unsupported cipher %d", h.cipher)
+	}
+
+	fields := make([][]byte, 3)
+	for i := range fields {
+		if len(rest) < 1 || len(rest) < 1+int(rest[0]) {
+			return h, nil, nil, errors.New("encrypted data is too short")
+		}
+		fields[i], rest = rest[1:1+int(rest[0])], rest[1+int(rest[0]):]
+	}
+	params := fields[0]
+	h.salt, h.nonce = fields[1], fields[2]
+
+	switch {
+	case kdfID == kdfIDNone && len(params) == 0 && len(h.salt) == 0:
+		h.kdf = KDFParams{Algorithm: KDFNone}
+		return h, data[:len(data)-len(rest)], rest, nil
+	case kdfID == kdfIDPBKDF2 && len(params) == 4:
+		h.kdf = KDFParams{Algorithm: KDFPBKDF2, Iterations: binary.BigEndian.Uint32(params)}
+	case kdfID == kdfIDArgon2id && len(params) == 9:
+		h.kdf = KDFParams{
+			Algorithm:   KDFArgon2id,
+			Memory:      binary.BigEndian.Uint32(params[0:4]),
+			Time:        binary.BigEndian.Uint32(params[4:8]),
+			Parallelism: params[8],
+		}
+	default:
+		return h, nil, nil, fmt.Errorf("unsupported KDF %d", kdfID)
+	}
+	if err := h.kdf.Validate(); err != nil {
+		return h, nil, nil, err
+	}
+	if len(h.salt) < 8 {
+		return h, nil, nil, errors.New("envelope salt is too short")
+	}
+
+	headerLen := len(data) - len(rest)
+	return h, data[:headerLen], rest, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// The golden files in testdata were encrypted with goldenPassword by the
+// encryption code of the genp release that introduced each format: the
+// headerless format of the first releases and envelope version 1. They must
+// never be regenerated: they prove that every released format can still be
+// decrypted.
+const (
+	goldenPassword  = "golden-master"
+	goldenPlaintext = "golden secret ✓"
+)
+
+func TestDecryptGoldenFiles(t *testing.T) {
+	tests := []struct {
+		file string
+		kdf  KDFParams
+	}{
+		{"headerless.txt", LegacyPBKDF2Params},
+		{"envelope-v1-pbkdf2.txt", KDFParams{Algorithm: KDFPBKDF2, Iterations: 1000}},
+		{"envelope-v1-argon2id.txt", KDFParams{Algorithm: KDFArgon2id, Memory: 64, Time: 1, Parallelism: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			data, err := os.ReadFile(filepath.Join("testdata", tt.file))
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			encrypted := strings.TrimSpace(string(data))
+
+			if kdf, err := KDFOf(encrypted); err != nil || kdf != tt.kdf {
+				t.Fatalf("KDFOf = %+v, %v; want %+v", kdf, err, tt.kdf)
+			}
+			decrypted, err := Decrypt(encrypted, goldenPassword)
+			if err != nil {
+				t.Fatalf("Decrypt failed: %v", err)
+			}
+			if decrypted != goldenPlaintext {
+				t.Fatalf("Decrypted %q, want %q", decrypted, goldenPlaintext)
+			}
+			if _, err := Decrypt(encrypted, "wrong"); err == nil || !strings.Contains(err.Error(), "failed to decrypt") {
+				t.Fatalf("Expected 'failed to decrypt' error for a wrong password, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDecryptWithKeyGoldenFile(t *testing.T) {
+	data, err := os.ReadFile(filepath.Join("testdata", "envelope-v1-key.txt"))
+	if err != nil {
+		t.Fatalf("Failed to read golden file: %v", err)
+	}
+	encrypted := strings.TrimSpace(string(data))
+	key := make([]byte, KeySize)
+	for i := range key {
+		key[i] = byte(i)
+	}
+
+	if !UsesKey(encrypted) {
+		t.Fatal("UsesKey = false for data encrypted with a key")
+	}
+	decrypted, err := DecryptWithKey(encrypted, key, nil)
+	if err != nil || decrypted != goldenPlaintext {
+		t.Fatalf("DecryptWithKey = %q, %v; want %q", decrypted, err, goldenPlaintext)
+	}
+	if _, err := Decrypt(encrypted, goldenPassword); err == nil {
+		t.Fatal("Decrypt with a password accepted data encrypted with a key")
+	}
+}
+
+func TestEncryptWritesCurrentEnvelope(t *testing.T) {
+	encrypted, err := EncryptWithKDF("secret", "master", fastArgon2id)
+	if err != nil {
+		t.Fatalf("EncryptWithKDF failed: %v", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		t.Fatalf("Ciphertext is not base64: %v", err)
+	}
+
+	header, _, ciphertext, err := parseEnvelope(data)
+	if err != nil {
+		t.Fatalf("parseEnvelope failed: %v", err)
+	}
+	if header.version != EnvelopeVersion || header.cipher != cipherAES256GCM || header.kdf != fastArgon2id {
+		t.Fatalf("Unexpected header %+v", header)
+	}
+	if len(header.salt) != SaltSize || len(header.nonce) != NonceSize || len(ciphertext) != len("secret")+16 {
+		t.Fatalf("Unexpected sizes: salt %d, nonce %d, ciphertext %d", len(header.salt), len(header.nonce), len(ciphertext))
+	}
+}
+
+func TestDecryptRejectsTamperedEnvelope(t *testing.T) {
+	encrypted, err := EncryptWithKDF("secret", "master", KDFParams{Algorithm: KDFPBKDF2, Iterations: 1000})
+	if err != nil {
+		t.Fatalf("EncryptWithKDF failed: %v", err)
+	}
+	data, _ := base64.StdEncoding.DecodeString(encrypted)
+
+	tests := map[string]func([]byte){
+		// The header is authenticated, so even a valid change is detected
+		"iterations": func(b []byte) { b[11]++ },
+		"version":    func(b []byte) { b[4] = 2 },
+		"cipher":     func(b []byte) { b[5] = 9 },
+		"ciphertext": func(b []byte) { b[len(b)-1] ^= 1 },
+	}
+	for name, tamper := range tests {
+		t.Run(name, func(t *testing.T) {
+			tampered := append([]byte(nil), data...)
+			tamper(tampered)
+			if _, err := Decrypt(base64.StdEncoding.EncodeToString(tampered), "master"); err == nil {
+				t.Fatal("Decrypt accepted a tampered envelope")
+			}
+		})
+	}
+
+	truncated := base64.StdEncoding.EncodeToString(data[:12])
+	if _, err := Decrypt(truncated, "master"); err == nil {
+		t.Fatal("Decrypt accepted a truncated envelope")
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+// Names of the supported key derivation functions. KDFNone marks data
+// encrypted with a key given directly, see EncryptWithKey.
+const (
+	KDFPBKDF2   = "pbkdf2-sha256"
+	KDFArgon2id = "argon2id"
+	KDFNone     = "none"
+)
+
+// Upper bounds for KDF parameters. Parameters are read from ciphertexts
+// that may come from a synced vault, so absurd values are refused rather
+// than allowed to exhaust memory or CPU.
+const (
+	maxPBKDF2Iterations = 10_000_000
+	maxArgon2Memory     = 4 * 1024 * 1024 // KiB, 4 GiB
+	maxArgon2Time       = 100
+
+	// MaxArgon2MemoryMiB is the largest Argon2id memory cost in MiB.
+	MaxArgon2MemoryMiB = maxArgon2Memory / 1024
+)
+
+// KDFParams selects a key derivation function and its cost parameters.
+type KDFParams struct {
+	Algorithm string `yaml:"algorithm"`
+	// Iterations is the PBKDF2 iteration count.
+	Iterations uint32 `yaml:"iterations,omitempty"`
+	// Memory is the Argon2id memory cost in KiB.
+	Memory uint32 `yaml:"memory,omitempty"`
+	// Time is the number of Argon2id passes.
+	Time uint32 `yaml:"time,omitempty"`
+	// Parallelism is the number of Argon2id lanes.
+	Parallelism uint8 `yaml:"parallelism,omitempty"`
+}
+
+var (
+	// LegacyPBKDF2Params are the parameters of every ciphertext written
+	// before the KDF was recorded in it.
+	LegacyPBKDF2Params = KDFParams{Algorithm: KDFPBKDF2, Iterations: Iterations}
+	// DefaultPBKDF2Params are used when a vault is rekeyed to PBKDF2.
+	DefaultPBKDF2Params = KDFParams{Algorithm: KDFPBKDF2, Iterations: 600_000}
+	// DefaultArgon2idParams are used for new ciphertexts unless the vault
+	// settings choose others.
+	DefaultArgon2idParams = KDFParams{Algorithm: KDFArgon2id, Memory: 64 * 1024, Time: 3, Parallelism: 4}
+)
+
+// defaultKDF is used by Encrypt. See SetDefaultKDF.
+var defaultKDF = DefaultArgon2idParams
+
+// SetDefaultKDF chooses the KDF used by Encrypt from now on.
+func SetDefaultKDF(params KDFParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+	defaultKDF = params
+	return nil
+}
+
+// DefaultKDF returns the KDF used by Encrypt.
+func DefaultKDF() KDFParams {
+	return defaultKDF
+}
+
+// Validate checks that the parameters are usable and within sane bounds.
+func (p KDFParams) Validate() error {
+	switch p.Algorithm {
+	case KDFPBKDF2:
+		if p.Iterations < 1 || p.Iterations > maxPBKDF2Iterations {
+			return fmt.Errorf("PBKDF2 iterations must be between 1 and %d, got %d", maxPBKDF2Iterations, p.Iterations)
+		}
+	case KDFArgon2id:
+		if p.Time < 1 || p.Time > maxArgon2Time {
+			return fmt.Errorf("argon2id time must be between 1 and %d, got %d", maxArgon2Time, p.Time)
+		}
+		if p.Parallelism < 1 {
+			return errors.New("argon2id parallelism must be at least 1")
+		}
+		if p.Memory < 8*uint32(p.Parallelism) || p.Memory > maxArgon2Memory {
+			return fmt.Errorf("argon2id memory must be between %d and %d KiB, got %d", 8*uint32(p.Parallelism), maxArgon2Memory, p.Memory)
+		}
+	default:
+		return fmt.Errorf("unknown KDF %q (use %s or %s)", p.Algorithm, KDFArgon2id, KDFPBKDF2)
+	}
+	return nil
+}
+
+// String describes the parameters, e.g. "argon2id m=65536,t=3,p=4".
+func (p KDFParams) String() string {
+	if p.Algorithm == KDFPBKDF2 {
+		return fmt.Sprintf("%s i=%d", p.Algorithm, p.Iterations)
+	}
+	return fmt.Sprintf("%s m=%d,t=%d,p=%d", p.Algorithm, p.Memory, p.Time, p.Parallelism)
+}
+
+// deriveKey stretches password into an encryption key.
+func (p KDFParams) deriveKey(password string, salt []byte) []byte {
+	if p.Algorithm == KDFArgon2id {
+		return argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Parallelism, KeySize)
+	}
+	return pbkdf2.Key([]byte(password), salt, int(p.Iterations), KeySize, sha256.New)
+}
+
+// KDFOf returns the KDF parameters recorded in a ciphertext produced by
+// Encrypt, without decrypting it.
+func KDFOf(encryptedData string) (KDFParams, error) {
+	data, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return KDFParams{}, fmt.Errorf("failed to decode base64: %w", err)
+	}
+	if !hasEnvelopeMagic(data) {
+		return LegacyPBKDF2Params, nil
+	}
+	header, _, _, err := parseEnvelope(data)
+	if err != nil {
+		return KDFParams{}, err
+	}
+	return header.kdf, nil
+}
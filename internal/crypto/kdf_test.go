@@ -0,0 +1,78 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+// fastArgon2id keeps the tests quick; the format is the same as with the
+// default parameters.
+var fastArgon2id = KDFParams{Algorithm: KDFArgon2id, Memory: 64, Time: 1, Parallelism: 1}
+
+func TestEncryptRecordsKDF(t *testing.T) {
+	for _, params := range []KDFParams{fastArgon2id, {Algorithm: KDFPBKDF2, Iterations: 1000}} {
+		encrypted, err := EncryptWithKDF("secret", "master", params)
+		if err != nil {
+			t.Fatalf("EncryptWithKDF(%s) failed: %v", params, err)
+		}
+		recorded, err := KDFOf(encrypted)
+		if err != nil || recorded != params {
+			t.Fatalf("KDFOf = %+v, %v; want %+v", recorded, err, params)
+		}
+		decrypted, err := Decrypt(encrypted, "master")
+		if err != nil || decrypted != "secret" {
+			t.Fatalf("Decrypt = %q, %v", decrypted, err)
+		}
+	}
+}
+
+func TestDecryptRejectsBadKDFParameters(t *testing.T) {
+	tests := map[string]KDFParams{
+		"argon2id memory":   {Algorithm: KDFArgon2id, Memory: 99999999, Time: 1, Parallelism: 1},
+		"argon2id time":     {Algorithm: KDFArgon2id, Memory: 64, Time: 0, Parallelism: 1},
+		"argon2id threads":  {Algorithm: KDFArgon2id, Memory: 64, Time: 1, Parallelism: 0},
+		"pbkdf2 iterations": {Algorithm: KDFPBKDF2, Iterations: 0},
+	}
+	for name, params := range tests {
+		header, err := envelopeHeader{
+			version: EnvelopeVersion,
+			cipher:  cipherAES256GCM,
+			kdf:     params,
+			salt:    make([]byte, SaltSize),
+			nonce:   make([]byte, NonceSize),
+		}.marshal()
+		if err != nil {
+			t.Fatalf("%s: marshal failed: %v", name, err)
+		}
+		encrypted := base64.StdEncoding.EncodeToString(append(header, make([]byte, 32)...))
+		if _, err := Decrypt(encrypted, "master"); err == nil {
+			t.Errorf("Decrypt with bad %s should fail", name)
+		}
+		if _, err := KDFOf(encrypted); err == nil {
+			t.Errorf("KDFOf with bad %s should fail", name)
+		}
+	}
+}
+
+func TestSetDefaultKDF(t *testing.T) {
+	t.Cleanup(func() { defaultKDF = DefaultArgon2idParams })
+
+	if err := SetDefaultKDF(KDFParams{Algorithm: KDFArgon2id}); err == nil {
+		t.Fatal("SetDefaultKDF should reject incomplete parameters")
+	}
+	if err := SetDefaultKDF(fastArgon2id); err != nil {
+		t.Fatalf("SetDefaultKDF failed: %v", err)
+	}
+	encrypted, err := Encrypt("secret", "master")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if params, _ := KDFOf(encrypted); params != fastArgon2id {
+		t.Fatalf("Encrypt used %+v, want %+v", params, fastArgon2id)
+	}
+}
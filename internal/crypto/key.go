@@ -0,0 +1,118 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GenerateKey returns a random KeySize-byte key, e.g. a vault key.
+func GenerateKey() ([]byte, error) {
+	key := make([]byte, KeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, fmt.Errorf("failed to generate key: %w", err)
+	}
+	return key, nil
+}
+
+// EncryptWithKey encrypts the plaintext using AES-256-GCM with key itself,
+// without running a KDF. The output is an envelope like that of Encrypt.
+// ad is authenticated along with the envelope header but not stored, e.g.
+// to bind the ciphertext to where it is kept; DecryptWithKey needs the same
+// ad.
+func EncryptWithKey(plaintext string, key []byte, ad []byte) (string, error) {
+	if plaintext == "" {
+		return "", errors.New("plaintext cannot be empty")
+	}
+	if len(key) != KeySize {
+		return "", fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	// Generate nonce
+	nonce := make([]byte, NonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	header, err := envelopeHeader{
+		version: EnvelopeVersion,
+		cipher:  cipherAES256GCM,
+		kdf:     KDFParams{Algorithm: KDFNone},
+		nonce:   nonce,
+	}.marshal()
+	if err != nil {
+		return "", err
+	}
+
+	gcm, err := newGCM(key)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(gcm.Seal(header, nonce, []byte(plaintext), withAD(header, ad))), nil
+}
+
+// DecryptWithKey decrypts ciphertext produced by EncryptWithKey with the
+// same ad.
+func DecryptWithKey(encryptedData string, key []byte, ad []byte) (string, error) {
+	if encryptedData == "" {
+		return "", errors.New("encrypted data cannot be empty")
+	}
+	if len(key) != KeySize {
+		return "", fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encryptedData)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode base64: %w", err)
+	}
+	header, aad, ciphertext, err := parseEnvelope(data)
+	if err != nil {
+		return "", err
+	}
+	if header.kdf.Algorithm != KDFNone {
+		return "", errors.New("data is encrypted with a password, not a key")
+	}
+	return openEnvelope(header, withAD(aad, ad), ciphertext, key)
+}
+
+// withAD returns the additional data authenticated for an envelope: its
+// header followed by ad.
+func withAD(header []byte, ad []byte) []byte {
+	return append(append([]byte(nil), header...), ad...)
+}
+
+// UsesKey reports whether encryptedData was produced by EncryptWithKey
+// rather than with a password.
+func UsesKey(encryptedData string) bool {
+	params, err := KDFOf(encryptedData)
+	return err == nil && params.Algorithm == KDFNone
+}
+
+// WrapKey encrypts key with a key derived from password using params, so
+// it can be stored next to the data it protects.
+func WrapKey(key []byte, password string, params KDFParams) (string, error) {
+	if len(key) != KeySize {
+		return "", fmt.Errorf("key must be %d bytes, got %d", KeySize, len(key))
+	}
+	return EncryptWithKDF(base64.StdEncoding.EncodeToString(key), password, params)
+}
+
+// UnwrapKey decrypts a key wrapped by WrapKey. A wrong password fails.
+func UnwrapKey(wrapped string, password string) ([]byte, error) {
+	encoded, err := Decrypt(wrapped, password)
+	if err != nil {
+		return nil, err
+	}
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(key) != KeySize {
+		return nil, errors.New("wrapped data is not a key")
+	}
+	return key, nil
+}
@@ -0,0 +1,68 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestEncryptWithKeyRoundTrip(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	ad := []byte("github/password")
+	encrypted, err := EncryptWithKey("secret", key, ad)
+	if err != nil {
+		t.Fatalf("EncryptWithKey failed: %v", err)
+	}
+	decrypted, err := DecryptWithKey(encrypted, key, ad)
+	if err != nil || decrypted != "secret" {
+		t.Fatalf("DecryptWithKey = %q, %v", decrypted, err)
+	}
+
+	other, _ := GenerateKey()
+	if _, err := DecryptWithKey(encrypted, other, ad); err == nil {
+		t.Fatal("DecryptWithKey accepted the wrong key")
+	}
+	// The additional data binds the ciphertext to its place
+	for _, moved := range [][]byte{nil, []byte("gitlab/password")} {
+		if _, err := DecryptWithKey(encrypted, key, moved); err == nil {
+			t.Fatalf("DecryptWithKey accepted additional data %q", moved)
+		}
+	}
+	byPassword, _ := EncryptWithKDF("secret", "master", fastArgon2id)
+	if UsesKey(byPassword) {
+		t.Fatal("UsesKey = true for data encrypted with a password")
+	}
+	if _, err := DecryptWithKey(byPassword, key, nil); err == nil {
+		t.Fatal("DecryptWithKey accepted data encrypted with a password")
+	}
+}
+
+func TestWrapKey(t *testing.T) {
+	key, err := GenerateKey()
+	if err != nil {
+		t.Fatalf("GenerateKey failed: %v", err)
+	}
+	wrapped, err := WrapKey(key, "master", fastArgon2id)
+	if err != nil {
+		t.Fatalf("WrapKey failed: %v", err)
+	}
+
+	unwrapped, err := UnwrapKey(wrapped, "master")
+	if err != nil || !bytes.Equal(unwrapped, key) {
+		t.Fatalf("UnwrapKey = %x, %v; want %x", unwrapped, err, key)
+	}
+	if _, err := UnwrapKey(wrapped, "wrong"); err == nil {
+		t.Fatal("UnwrapKey accepted the wrong password")
+	}
+	notAKey, _ := EncryptWithKDF("secret", "master", fastArgon2id)
+	if _, err := UnwrapKey(notAKey, "master"); err == nil {
+		t.Fatal("UnwrapKey accepted data that is not a key")
+	}
+}
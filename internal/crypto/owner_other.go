@@ -0,0 +1,14 @@
+//go:build !unix
+
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import "os"
+
+// ownedByRoot is always false on platforms without root, so only the
+// default PAM helper is accepted there.
+func ownedByRoot(info os.FileInfo) bool { return false }
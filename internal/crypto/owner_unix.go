@@ -0,0 +1,19 @@
+//go:build unix
+
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package crypto
+
+import (
+	"os"
+	"syscall"
+)
+
+// ownedByRoot reports whether the file described by info belongs to root.
+func ownedByRoot(info os.FileInfo) bool {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	return ok && stat.Uid == 0
+}
@@ -14,6 +14,21 @@ import (
 	"golang.org/x/term"
 )
 
+// PasswordReader shows prompt and reads a secret without echoing it.
+type PasswordReader func(prompt string) (string, error)
+
+// readPassword is used by PromptForPassword. See SetPasswordReader.
+var readPassword PasswordReader = readTerminalPassword
+
+// SetPasswordReader replaces how PromptForPassword reads secrets, e.g.
+// with scripted answers in tests. It returns a function that restores the
+// previous reader.
+func SetPasswordReader(r PasswordReader) (restore func()) {
+	previous := readPassword
+	readPassword = r
+	return func() { readPassword = previous }
+}
+
 // PromptForMasterPassword prompts the user to enter their system lock screen
 // password once (without echoing) and verifies it against the OS.
 // On success it returns the verified password for use as the encryption key.
@@ -21,29 +36,58 @@ func PromptForMasterPassword(promptText string) (string, error) {
 	if promptText == "" {
 		promptText = "Enter system password: "
 	}
+	return PromptAndVerify(promptText, SystemAuthenticator())
+}
 
-	color.New(color.FgMagenta).Print(promptText)
+// PromptAndVerify asks for the system password if auth needs one and
+// verifies the user with auth. It returns the password, which is empty for
+// authenticators that do not need one.
+func PromptAndVerify(promptText string, auth Authenticator) (string, error) {
+	var password string
+	if auth.NeedsPassword() {
+		var err error
+		if password, err = PromptForPassword(promptText); err != nil {
+			return "", err
+		}
+	}
 
-	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
-	fmt.Println()
+	if err := auth.Verify(password); err != nil {
+		return "", fmt.Errorf("authentication failed: %w", err)
+	}
+
+	return password, nil
+}
 
+// PromptForPassword prompts for a secret without echoing it and returns it
+// with surrounding whitespace removed. Unlike PromptForMasterPassword it does
+// not verify the input against the operating system.
+func PromptForPassword(promptText string) (string, error) {
+	secret, err := readPassword(promptText)
 	if err != nil {
-		return "", fmt.Errorf("failed to read password: %w", err)
+		return "", err
 	}
 
-	password := strings.TrimSpace(string(bytePassword))
+	password := strings.TrimSpace(secret)
 	if password == "" {
 		return "", fmt.Errorf("password cannot be empty")
 	}
 
-	// Verify the password against the operating system
-	if err := VerifySystemPassword(password); err != nil {
-		return "", fmt.Errorf("authentication failed: %w", err)
-	}
-
 	return password, nil
 }
 
+// readTerminalPassword reads a secret from the terminal without echoing it.
+func readTerminalPassword(promptText string) (string, error) {
+	color.New(color.FgMagenta).Print(promptText)
+
+	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Println()
+
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(bytePassword), nil
+}
+
 // CheckMasterPasswordExists checks if a config file already exists at the
 // given path, which indicates passwords have been stored before.
 func CheckMasterPasswordExists(configPath string) bool {
@@ -8,28 +8,49 @@ import (
 	"fmt"
 	"os/exec"
 	"os/user"
-	"runtime"
 	"strings"
 )
 
 // VerifySystemPassword verifies the given password against the operating system's
-// user account password (the same password used to unlock the lock screen).
+// user account password (the same password used to unlock the lock screen)
+// with SystemAuthenticator.
 // Returns nil if the password is correct, or an error describing the failure.
 func VerifySystemPassword(password string) error {
+	return SystemAuthenticator().Verify(password)
+}
+
+// DSCLAuthenticator checks the system password on macOS.
+type DSCLAuthenticator struct{}
+
+// Name returns AuthSystem.
+func (DSCLAuthenticator) Name() string { return AuthSystem }
+
+// NeedsPassword returns true.
+func (DSCLAuthenticator) NeedsPassword() bool { return true }
+
+// Verify authenticates the current user with dscl.
+func (DSCLAuthenticator) Verify(password string) error {
 	if password == "" {
 		return fmt.Errorf("password cannot be empty")
 	}
+	return verifyMacOS(password)
+}
+
+// WindowsAuthenticator checks the system password on Windows.
+type WindowsAuthenticator struct{}
 
-	switch runtime.GOOS {
-	case "darwin":
-		return verifyMacOS(password)
-	case "linux":
-		return verifyLinux(password)
-	case "windows":
-		return verifyWindows(password)
-	default:
-		return fmt.Errorf("unsupported operating system: %s", runtime.GOOS)
+// Name returns AuthSystem.
+func (WindowsAuthenticator) Name() string { return AuthSystem }
+
+// NeedsPassword returns true.
+func (WindowsAuthenticator) NeedsPassword() bool { return true }
+
+// Verify validates the password against the local account database.
+func (WindowsAuthenticator) Verify(password string) error {
+	if password == "" {
+		return fmt.Errorf("password cannot be empty")
 	}
+	return verifyWindows(password)
 }
 
 // verifyMacOS uses dscl to authenticate the current user against the local directory.
@@ -53,32 +74,6 @@ func verifyMacOS(password string) error {
 	return nil
 }
 
-// verifyLinux uses sudo to validate the current user's password.
-// It pipes the password into `sudo -k -S -v` which validates without running a command.
-// -k invalidates the cached credentials first so it always prompts.
-// -S reads the password from stdin.
-// -v updates the cached credentials (validates) without running a command.
-func verifyLinux(password string) error {
-	cmd := exec.Command("sudo", "-k", "-S", "-v")
-	cmd.Stdin = strings.NewReader(password + "\n")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		outStr := strings.TrimSpace(string(output))
-		if strings.Contains(outStr, "incorrect password") || strings.Contains(outStr, "Sorry") {
-			return fmt.Errorf("system password verification failed: incorrect password")
-		}
-		if outStr != "" {
-			return fmt.Errorf("system password verification failed: %s", outStr)
-		}
-		return fmt.Errorf("system password verification failed: incorrect password")
-	}
-
-	// Immediately invalidate the sudo timestamp so we don't leave an open sudo session
-	_ = exec.Command("sudo", "-k").Run()
-
-	return nil
-}
-
 // verifyWindows uses PowerShell and the .NET DirectoryServices to validate the
 // current user's password against the local machine account database.
 func verifyWindows(password string) error {
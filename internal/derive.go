@@ -0,0 +1,90 @@
+package internal
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+// DerivePassword derives the site password of 'genp derive': the site key
+// from crypto.DeriveSiteKey drives a deterministic generator that obeys
+// opts. opts.Rand is ignored.
+//
+// The result must never change for the same inputs, so the generator is a
+// frozen copy of GenerateWithOptions (derivation scheme v1) rather than the
+// function itself, and the alphabet built by PasswordOptions.classes is part
+// of the scheme as well. TestDerivePasswordKnownAnswers pins the output.
+func DerivePassword(masterSecret, site, login string, counter uint32, opts PasswordOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+	key, err := crypto.DeriveSiteKey(masterSecret, site, login, counter)
+	if err != nil {
+		return "", err
+	}
+	r, err := crypto.NewDeterministicReader(key)
+	if err != nil {
+		return "", err
+	}
+	return deriveV1(r, opts)
+}
+
+// deriveV1 draws the required characters of every class in class order,
+// fills up from the whole alphabet and shuffles the result, all with
+// deriveIndexV1. Do not change it; add a new scheme version instead.
+func deriveV1(r io.Reader, opts PasswordOptions) (string, error) {
+	classes, err := opts.classes()
+	if err != nil {
+		return "", err
+	}
+	charset := ""
+	for _, class := range classes {
+		charset += class.chars
+	}
+
+	password := make([]byte, 0, opts.Length)
+	for _, class := range classes {
+		for i := 0; i < class.min; i++ {
+			idx, err := deriveIndexV1(r, len(class.chars))
+			if err != nil {
+				return "", fmt.Errorf("failed to pick %s character: %w", class.name, err)
+			}
+			password = append(password, class.chars[idx])
+		}
+	}
+	for len(password) < opts.Length {
+		idx, err := deriveIndexV1(r, len(charset))
+		if err != nil {
+			return "", fmt.Errorf("failed to pick character: %w", err)
+		}
+		password = append(password, charset[idx])
+	}
+
+	// Fisher-Yates, from the last position down
+	for i := len(password) - 1; i > 0; i-- {
+		j, err := deriveIndexV1(r, i+1)
+		if err != nil {
+			return "", fmt.Errorf("failed to shuffle password: %w", err)
+		}
+		password[i], password[j] = password[j], password[i]
+	}
+	return string(password), nil
+}
+
+// deriveIndexV1 returns an index in [0, n) from big-endian 32-bit words of
+// r, rejecting words at or above the largest multiple of n.
+func deriveIndexV1(r io.Reader, n int) (int, error) {
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(n)
+	var buf [4]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrRandomSource, err)
+		}
+		v := uint64(binary.BigEndian.Uint32(buf[:]))
+		if v < limit {
+			return int(v % uint64(n)), nil
+		}
+	}
+}
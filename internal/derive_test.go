@@ -0,0 +1,112 @@
+package internal
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+// TestDerivePasswordKnownAnswers pins the passwords of 'genp derive'. If it
+// fails, every password users derived before would change: fix the code,
+// never the expected values.
+func TestDerivePasswordKnownAnswers(t *testing.T) {
+	tests := []struct {
+		secret, site, login string
+		counter             uint32
+		opts                PasswordOptions
+		want                string
+	}{
+		{"correct horse battery staple", "example.com", "alice", 1,
+			PasswordOptions{Length: 16},
+			"abjbntzmdemptlam"},
+		{"correct horse battery staple", "Example.com ", "alice", 2,
+			PasswordOptions{Length: 20, Numbers: true, Uppercase: true, Special: true},
+			"@3y071wVB8tBMOnE7LiY"},
+		{"correct horse battery staple", "aws.amazon.com", "root", 1,
+			PasswordOptions{Length: 24, Numbers: true, Uppercase: true, Special: true, MinDigits: 3, MinSpecial: 2, Symbols: "%*+-", NoAmbiguous: true},
+			"i*3-WUkg-mujPgPg8j8rzF*p"},
+		{"s3cret", "bank.example", "", 7,
+			PasswordOptions{Length: 12, Charset: "abcdef0123456789", MinDigits: 2, Exclude: "0"},
+			"35d1a38558bd"},
+	}
+	for _, tt := range tests {
+		got, err := DerivePassword(tt.secret, tt.site, tt.login, tt.counter, tt.opts)
+		if err != nil {
+			t.Fatalf("DerivePassword(%s, %s, %d) failed: %v", tt.site, tt.login, tt.counter, err)
+		}
+		if got != tt.want {
+			t.Errorf("DerivePassword(%s, %s, %d) = %q, want %q", tt.site, tt.login, tt.counter, got, tt.want)
+		}
+	}
+}
+
+func TestDerivePasswordValidatesOptions(t *testing.T) {
+	if _, err := DerivePassword("s3cret", "example.com", "", 1, PasswordOptions{Length: 0}); !errors.Is(err, ErrInvalidLength) {
+		t.Fatalf("Expected ErrInvalidLength, got %v", err)
+	}
+	if _, err := DerivePassword("", "example.com", "", 1, PasswordOptions{Length: 8}); err == nil {
+		t.Fatal("DerivePassword accepted an empty master secret")
+	}
+}
+
+func TestDeriveSiteKeyIsDeterministic(t *testing.T) {
+	key1, err := crypto.DeriveSiteKey("master", "Example.com", "alice", 1)
+	if err != nil {
+		t.Fatalf("DeriveSiteKey failed: %v", err)
+	}
+	key2, err := crypto.DeriveSiteKey("master", " example.com ", "alice", 1)
+	if err != nil {
+		t.Fatalf("DeriveSiteKey failed: %v", err)
+	}
+	if string(key1) != string(key2) {
+		t.Fatal("Same site and login produced different keys")
+	}
+
+	for _, other := range []struct {
+		secret, site, login string
+		counter             uint32
+	}{
+		{"master", "example.com", "alice", 2},
+		{"master", "example.com", "bob", 1},
+		{"master", "example.org", "alice", 1},
+		{"other", "example.com", "alice", 1},
+	} {
+		key, err := crypto.DeriveSiteKey(other.secret, other.site, other.login, other.counter)
+		if err != nil {
+			t.Fatalf("DeriveSiteKey failed: %v", err)
+		}
+		if string(key) == string(key1) {
+			t.Fatalf("Inputs %+v produced the same key", other)
+		}
+	}
+}
+
+func TestDeriveSiteKeyRequiresSecretAndSite(t *testing.T) {
+	if _, err := crypto.DeriveSiteKey("", "example.com", "alice", 1); err == nil {
+		t.Fatal("Expected error for empty master secret, got nil")
+	}
+	if _, err := crypto.DeriveSiteKey("master", "  ", "alice", 1); err == nil {
+		t.Fatal("Expected error for empty site, got nil")
+	}
+}
+
+func TestDeterministicReader(t *testing.T) {
+	key := make([]byte, crypto.KeySize)
+	r1, err := crypto.NewDeterministicReader(key)
+	if err != nil {
+		t.Fatalf("NewDeterministicReader failed: %v", err)
+	}
+	r2, _ := crypto.NewDeterministicReader(key)
+
+	buf1 := make([]byte, 64)
+	buf2 := make([]byte, 64)
+	r1.Read(buf1)
+	r2.Read(buf2)
+	if string(buf1) != string(buf2) {
+		t.Fatal("Same key produced different streams")
+	}
+	if string(buf1) == string(make([]byte, 64)) {
+		t.Fatal("Stream is all zeros")
+	}
+}
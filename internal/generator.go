@@ -2,7 +2,11 @@ package internal
 
 import (
 	"crypto/rand"
-	"math/big"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
 )
 
 const (
@@ -10,32 +14,323 @@ const (
 	uppercaseBytes = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
 	numberBytes    = "0123456789"
 	specialBytes   = "!@#$&"
+	// ambiguousBytes are characters that are easily confused with each
+	// other in many fonts.
+	ambiguousBytes = "0O1lI|"
+
+	// MaxPasswordLength is the longest password the generators produce.
+	MaxPasswordLength = 4096
+)
+
+var (
+	// ErrInvalidLength is returned for a length outside 1..MaxPasswordLength.
+	ErrInvalidLength = fmt.Errorf("password length must be between 1 and %d", MaxPasswordLength)
+	// ErrInvalidMinimum is returned when a per-class minimum is negative.
+	ErrInvalidMinimum = errors.New("minimum character counts must not be negative")
 )
 
-func GeneratePassword(length int, includeNumbers, includeUppercase, includeSpecial bool) string {
-	charset := lowercaseBytes
+// PasswordOptions describes the character classes and per-class minimums
+// used by GenerateWithOptions. Lowercase letters are always included.
+// A positive minimum enables its class even if the matching flag is false.
+//
+// When Charset is set it replaces the class-based alphabet entirely: the
+// class flags are ignored and the minimums apply to the lowercase, uppercase,
+// digit and other characters found in Charset.
+type PasswordOptions struct {
+	Length    int
+	Numbers   bool
+	Uppercase bool
+	Special   bool
+
+	MinLower   int
+	MinUpper   int
+	MinDigits  int
+	MinSpecial int
+
+	Charset     string // custom alphabet, replaces the class-based one
+	Symbols     string // special characters to use instead of !@#$&
+	Exclude     string // characters that must never appear
+	NoAmbiguous bool   // drop look-alike characters such as 0O1lI
+
+	Rand io.Reader // source of randomness, crypto/rand when nil
+}
+
+// random returns the source of randomness for opts.
+func (o PasswordOptions) random() io.Reader {
+	if o.Rand != nil {
+		return o.Rand
+	}
+	return rand.Reader
+}
+
+// Validate checks the length and per-class minimums of opts, as well as
+// whether the selected classes can satisfy them.
+func (o PasswordOptions) Validate() error {
+	if o.Length < 1 || o.Length > MaxPasswordLength {
+		return ErrInvalidLength
+	}
+	if o.MinLower < 0 || o.MinUpper < 0 || o.MinDigits < 0 || o.MinSpecial < 0 {
+		return ErrInvalidMinimum
+	}
 
-	if includeUppercase {
-		charset += uppercaseBytes
+	classes, err := o.classes()
+	if err != nil {
+		return err
 	}
-	if includeNumbers {
-		charset += numberBytes
+	required := 0
+	for _, class := range classes {
+		required += class.min
 	}
-	if includeSpecial {
-		charset += specialBytes
+	if required > o.Length {
+		return fmt.Errorf("password length %d cannot fit the %d required characters", o.Length, required)
 	}
+	return nil
+}
 
-	password := make([]byte, length)
-	charsetLength := big.NewInt(int64(len(charset)))
+// charClass is one group of characters together with the number of
+// characters that must be drawn from it.
+type charClass struct {
+	name  string
+	chars string
+	min   int
+}
 
-	for i := 0; i < length; i++ {
-		randomIndex, err := rand.Int(rand.Reader, charsetLength)
-		if err != nil {
-			password[i] = charset[0]
+// classes returns the enabled character classes with excluded characters
+// removed. Outside of custom charset mode every enabled class requires at
+// least one character.
+//
+// The classes, their order and their characters are part of the derivation
+// scheme of DerivePassword, so changing them changes derived passwords.
+func (o PasswordOptions) classes() ([]charClass, error) {
+	drop := o.Exclude
+	if o.NoAmbiguous {
+		drop += ambiguousBytes
+	}
+
+	if o.Charset != "" {
+		return o.customClasses(drop)
+	}
+
+	symbols, err := symbolSet(o.Symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	classes := []charClass{{name: "lowercase", chars: lowercaseBytes, min: max(o.MinLower, 1)}}
+	if o.Uppercase || o.MinUpper > 0 {
+		classes = append(classes, charClass{name: "uppercase", chars: uppercaseBytes, min: max(o.MinUpper, 1)})
+	}
+	if o.Numbers || o.MinDigits > 0 {
+		classes = append(classes, charClass{name: "digit", chars: numberBytes, min: max(o.MinDigits, 1)})
+	}
+	if o.Special || o.MinSpecial > 0 {
+		classes = append(classes, charClass{name: "special", chars: symbols, min: max(o.MinSpecial, 1)})
+	}
+
+	for i := range classes {
+		classes[i].chars = filterChars(classes[i].chars, drop)
+		if classes[i].chars == "" {
+			return nil, fmt.Errorf("every %s character is excluded", classes[i].name)
+		}
+	}
+	return classes, nil
+}
+
+// customClasses splits the custom charset into lowercase, uppercase, digit
+// and special classes so the minimums can still be enforced.
+func (o PasswordOptions) customClasses(drop string) ([]charClass, error) {
+	if err := checkPrintableASCII("charset", o.Charset); err != nil {
+		return nil, err
+	}
+
+	charset := filterChars(o.Charset, drop)
+	if charset == "" {
+		return nil, errors.New("every character of the charset is excluded")
+	}
+
+	classes := []charClass{
+		{name: "lowercase", min: o.MinLower},
+		{name: "uppercase", min: o.MinUpper},
+		{name: "digit", min: o.MinDigits},
+		{name: "special", min: o.MinSpecial},
+	}
+	for i := 0; i < len(charset); i++ {
+		ch := charset[i]
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			classes[0].chars += string(ch)
+		case ch >= 'A' && ch <= 'Z':
+			classes[1].chars += string(ch)
+		case ch >= '0' && ch <= '9':
+			classes[2].chars += string(ch)
+		default:
+			classes[3].chars += string(ch)
+		}
+	}
+
+	nonEmpty := classes[:0]
+	for _, class := range classes {
+		if class.chars == "" {
+			if class.min > 0 {
+				return nil, fmt.Errorf("charset has no %s characters but at least %d are required", class.name, class.min)
+			}
+			continue
+		}
+		nonEmpty = append(nonEmpty, class)
+	}
+	return nonEmpty, nil
+}
+
+// filterChars returns chars without duplicates and without any character
+// contained in drop.
+func filterChars(chars, drop string) string {
+	var b strings.Builder
+	for i := 0; i < len(chars); i++ {
+		ch := chars[i]
+		if strings.IndexByte(drop, ch) >= 0 || strings.IndexByte(b.String(), ch) >= 0 {
 			continue
 		}
-		password[i] = charset[randomIndex.Int64()]
+		b.WriteByte(ch)
 	}
+	return b.String()
+}
+
+// symbolSet returns the special characters to use: symbols, checked and
+// without duplicates, or !@#$& if symbols is empty.
+func symbolSet(symbols string) (string, error) {
+	if symbols == "" {
+		return specialBytes, nil
+	}
+	if err := checkPrintableASCII("symbol set", symbols); err != nil {
+		return "", err
+	}
+	return filterChars(symbols, ""), nil
+}
+
+// checkPrintableASCII rejects characters outside printable ASCII, since
+// passwords are assembled byte by byte.
+func checkPrintableASCII(what, chars string) error {
+	for i := 0; i < len(chars); i++ {
+		if chars[i] < '!' || chars[i] > '~' {
+			return fmt.Errorf("%s may only contain printable ASCII characters", what)
+		}
+	}
+	return nil
+}
+
+// GenerateWithOptions generates a password that contains at least the
+// requested number of characters from every enabled class. The required
+// characters are placed first and the whole password is then shuffled with
+// crypto/rand, so their positions are not predictable.
+func GenerateWithOptions(opts PasswordOptions) (string, error) {
+	if err := opts.Validate(); err != nil {
+		return "", err
+	}
+
+	classes, err := opts.classes()
+	if err != nil {
+		return "", err
+	}
+	charset := ""
+	for _, class := range classes {
+		charset += class.chars
+	}
+
+	r := opts.random()
+	password := make([]byte, 0, opts.Length)
+	for _, class := range classes {
+		for i := 0; i < class.min; i++ {
+			idx, err := randomIndex(r, len(class.chars))
+			if err != nil {
+				return "", fmt.Errorf("failed to pick %s character: %w", class.name, err)
+			}
+			password = append(password, class.chars[idx])
+		}
+	}
+	for len(password) < opts.Length {
+		idx, err := randomIndex(r, len(charset))
+		if err != nil {
+			return "", fmt.Errorf("failed to pick character: %w", err)
+		}
+		password = append(password, charset[idx])
+	}
+
+	if err := shuffle(r, password); err != nil {
+		return "", fmt.Errorf("failed to shuffle password: %w", err)
+	}
+	return string(password), nil
+}
+
+// PasswordEntropy returns an estimate of the entropy in bits of a password
+// generated with opts. It treats every character as drawn from the full
+// alphabet, which slightly overstates passwords with large minimums.
+func PasswordEntropy(opts PasswordOptions) (float64, error) {
+	classes, err := opts.classes()
+	if err != nil {
+		return 0, err
+	}
+
+	size := 0
+	for _, class := range classes {
+		size += len(class.chars)
+	}
+	return float64(opts.Length) * math.Log2(float64(size)), nil
+}
+
+// CharacterClasses reports which character classes occur in password,
+// in the order lowercase, uppercase, digit, special.
+func CharacterClasses(password string) []string {
+	var lower, upper, digit, special bool
+	for _, ch := range password {
+		switch {
+		case ch >= 'a' && ch <= 'z':
+			lower = true
+		case ch >= 'A' && ch <= 'Z':
+			upper = true
+		case ch >= '0' && ch <= '9':
+			digit = true
+		default:
+			special = true
+		}
+	}
+
+	classes := []string{}
+	if lower {
+		classes = append(classes, "lowercase")
+	}
+	if upper {
+		classes = append(classes, "uppercase")
+	}
+	if digit {
+		classes = append(classes, "digit")
+	}
+	if special {
+		classes = append(classes, "special")
+	}
+	return classes
+}
+
+// shuffle performs an in-place Fisher-Yates shuffle with randomness from r.
+func shuffle(r io.Reader, b []byte) error {
+	for i := len(b) - 1; i > 0; i-- {
+		j, err := randomIndex(r, i+1)
+		if err != nil {
+			return err
+		}
+		b[i], b[j] = b[j], b[i]
+	}
+	return nil
+}
 
-	return string(password)
+// GeneratePassword generates a password of the given length that contains
+// at least one character from every selected class. It returns an error
+// instead of a password if the options are invalid or the random number
+// generator fails.
+func GeneratePassword(length int, includeNumbers, includeUppercase, includeSpecial bool) (string, error) {
+	return GenerateWithOptions(PasswordOptions{
+		Length:    length,
+		Numbers:   includeNumbers,
+		Uppercase: includeUppercase,
+		Special:   includeSpecial,
+	})
 }
@@ -0,0 +1,373 @@
+package internal
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func countIn(s, charset string) int {
+	n := 0
+	for _, ch := range s {
+		if strings.ContainsRune(charset, ch) {
+			n++
+		}
+	}
+	return n
+}
+
+func TestGenerateWithOptionsIncludesEverySelectedClass(t *testing.T) {
+	opts := PasswordOptions{Length: 4, Numbers: true, Uppercase: true, Special: true}
+
+	// With only four characters a uniform sampler misses a class most of
+	// the time, so a few hundred runs would catch a regression.
+	for i := 0; i < 500; i++ {
+		password, err := GenerateWithOptions(opts)
+		if err != nil {
+			t.Fatalf("GenerateWithOptions failed: %v", err)
+		}
+		if len(password) != opts.Length {
+			t.Fatalf("Expected length %d, got %d (%q)", opts.Length, len(password), password)
+		}
+		for _, charset := range []string{lowercaseBytes, uppercaseBytes, numberBytes, specialBytes} {
+			if countIn(password, charset) == 0 {
+				t.Fatalf("Password %q has no character from %q", password, charset)
+			}
+		}
+	}
+}
+
+func TestGenerateWithOptionsMinimums(t *testing.T) {
+	opts := PasswordOptions{Length: 10, MinDigits: 3, MinSpecial: 2, MinUpper: 2}
+
+	for i := 0; i < 100; i++ {
+		password, err := GenerateWithOptions(opts)
+		if err != nil {
+			t.Fatalf("GenerateWithOptions failed: %v", err)
+		}
+		if got := countIn(password, numberBytes); got < opts.MinDigits {
+			t.Fatalf("Password %q has %d digits, want at least %d", password, got, opts.MinDigits)
+		}
+		if got := countIn(password, specialBytes); got < opts.MinSpecial {
+			t.Fatalf("Password %q has %d special characters, want at least %d", password, got, opts.MinSpecial)
+		}
+		if got := countIn(password, uppercaseBytes); got < opts.MinUpper {
+			t.Fatalf("Password %q has %d uppercase letters, want at least %d", password, got, opts.MinUpper)
+		}
+	}
+}
+
+func TestGenerateWithOptionsConstraintsDoNotFit(t *testing.T) {
+	_, err := GenerateWithOptions(PasswordOptions{Length: 4, MinDigits: 3, MinSpecial: 2})
+	if err == nil {
+		t.Fatal("Expected error when minimums exceed the length, got nil")
+	}
+	if !strings.Contains(err.Error(), "cannot fit") {
+		t.Fatalf("Expected 'cannot fit' error, got: %v", err)
+	}
+}
+
+func TestGenerateWithOptionsNegativeMinimum(t *testing.T) {
+	_, err := GenerateWithOptions(PasswordOptions{Length: 8, MinDigits: -1})
+	if !errors.Is(err, ErrInvalidMinimum) {
+		t.Fatalf("Expected ErrInvalidMinimum, got: %v", err)
+	}
+}
+
+func TestGeneratePasswordInvalidLength(t *testing.T) {
+	for _, length := range []int{0, -1, MaxPasswordLength + 1} {
+		password, err := GeneratePassword(length, true, true, true)
+		if !errors.Is(err, ErrInvalidLength) {
+			t.Fatalf("Expected ErrInvalidLength for length %d, got: %v", length, err)
+		}
+		if password != "" {
+			t.Fatalf("Expected no password for length %d, got %q", length, password)
+		}
+	}
+}
+
+// failingReader simulates a broken random number generator.
+type failingReader struct{}
+
+func (failingReader) Read([]byte) (int, error) {
+	return 0, errors.New("entropy source unavailable")
+}
+
+func TestGenerateFailsWithBrokenRandomSource(t *testing.T) {
+	password, err := GenerateWithOptions(PasswordOptions{Length: 8, Rand: failingReader{}})
+	if !errors.Is(err, ErrRandomSource) {
+		t.Fatalf("Expected ErrRandomSource, got: %v", err)
+	}
+	if password != "" {
+		t.Fatalf("Expected no password from a broken random source, got %q", password)
+	}
+
+	_, err = GenerateFromTemplate("####", PasswordOptions{Rand: failingReader{}})
+	if !errors.Is(err, ErrRandomSource) {
+		t.Fatalf("Expected ErrRandomSource from template, got: %v", err)
+	}
+}
+
+func TestGenerateWithOptionsExcludesCharacters(t *testing.T) {
+	opts := PasswordOptions{Length: 64, Numbers: true, Uppercase: true, Special: true, Exclude: "aeiou#", NoAmbiguous: true}
+
+	for i := 0; i < 50; i++ {
+		password, err := GenerateWithOptions(opts)
+		if err != nil {
+			t.Fatalf("GenerateWithOptions failed: %v", err)
+		}
+		if strings.ContainsAny(password, "aeiou#"+ambiguousBytes) {
+			t.Fatalf("Password %q contains an excluded character", password)
+		}
+	}
+}
+
+func TestGenerateWithOptionsCustomSymbols(t *testing.T) {
+	opts := PasswordOptions{Length: 32, Special: true, Symbols: "%*", MinSpecial: 4}
+
+	password, err := GenerateWithOptions(opts)
+	if err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	if strings.ContainsAny(password, specialBytes) {
+		t.Fatalf("Password %q contains a default symbol", password)
+	}
+	if got := countIn(password, "%*"); got < opts.MinSpecial {
+		t.Fatalf("Password %q has %d custom symbols, want at least %d", password, got, opts.MinSpecial)
+	}
+}
+
+func TestGenerateWithOptionsCustomCharset(t *testing.T) {
+	opts := PasswordOptions{Length: 40, Charset: "abc123", Exclude: "c", MinDigits: 2}
+
+	password, err := GenerateWithOptions(opts)
+	if err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	for _, ch := range password {
+		if !strings.ContainsRune("ab123", ch) {
+			t.Fatalf("Password %q contains %q outside the charset", password, ch)
+		}
+	}
+	if got := countIn(password, numberBytes); got < opts.MinDigits {
+		t.Fatalf("Password %q has %d digits, want at least %d", password, got, opts.MinDigits)
+	}
+
+	_, err = GenerateWithOptions(PasswordOptions{Length: 8, Charset: "abc", MinUpper: 1})
+	if err == nil {
+		t.Fatal("Expected error when the charset lacks a required class, got nil")
+	}
+}
+
+func TestGenerateWithOptionsEverythingExcluded(t *testing.T) {
+	_, err := GenerateWithOptions(PasswordOptions{Length: 8, Numbers: true, Exclude: numberBytes})
+	if err == nil {
+		t.Fatal("Expected error when a selected class is fully excluded, got nil")
+	}
+	if !strings.Contains(err.Error(), "every digit character is excluded") {
+		t.Fatalf("Expected 'every digit character is excluded' error, got: %v", err)
+	}
+}
+
+func TestGenerateFromTemplate(t *testing.T) {
+	password, err := GenerateFromTemplate(`Xx-####-????-\X\#`, PasswordOptions{})
+	if err != nil {
+		t.Fatalf("GenerateFromTemplate failed: %v", err)
+	}
+	if len(password) != 15 {
+		t.Fatalf("Expected 15 characters, got %d (%q)", len(password), password)
+	}
+	if !strings.ContainsRune(uppercaseBytes, rune(password[0])) || !strings.ContainsRune(lowercaseBytes, rune(password[1])) {
+		t.Fatalf("Password %q does not start with an uppercase and a lowercase letter", password)
+	}
+	if countIn(password[3:7], numberBytes) != 4 {
+		t.Fatalf("Password %q does not have four digits in the middle", password)
+	}
+	if password[2] != '-' || password[7] != '-' || password[12] != '-' || password[13:] != "X#" {
+		t.Fatalf("Password %q does not keep the literals of the template", password)
+	}
+}
+
+func TestGenerateFromTemplateHonoursExclusions(t *testing.T) {
+	opts := PasswordOptions{Symbols: "%", Exclude: "0123", NoAmbiguous: true}
+	for i := 0; i < 50; i++ {
+		password, err := GenerateFromTemplate("$####****", opts)
+		if err != nil {
+			t.Fatalf("GenerateFromTemplate failed: %v", err)
+		}
+		if password[0] != '%' {
+			t.Fatalf("Password %q does not start with the custom symbol", password)
+		}
+		if strings.ContainsAny(password, "0123"+ambiguousBytes+specialBytes) {
+			t.Fatalf("Password %q contains an excluded character", password)
+		}
+	}
+}
+
+func TestGenerateFromTemplateErrors(t *testing.T) {
+	for _, template := range []string{"", "abc-def", `XX\`} {
+		if _, err := GenerateFromTemplate(template, PasswordOptions{}); err == nil {
+			t.Fatalf("Expected error for template %q, got nil", template)
+		}
+	}
+}
+
+func TestTemplateEntropy(t *testing.T) {
+	bits, err := TemplateEntropy("##", PasswordOptions{})
+	if err != nil {
+		t.Fatalf("TemplateEntropy failed: %v", err)
+	}
+	// Two digits give 100 combinations
+	if bits < 6.64 || bits > 6.65 {
+		t.Fatalf("Expected about 6.64 bits, got %f", bits)
+	}
+}
+
+func TestGeneratePronounceable(t *testing.T) {
+	opts := PronounceableOptions{Length: 11, Capitalize: true, AddDigit: true, AddSymbol: true}
+
+	for i := 0; i < 100; i++ {
+		password, err := GeneratePronounceable(opts)
+		if err != nil {
+			t.Fatalf("GeneratePronounceable failed: %v", err)
+		}
+		if len(password) != opts.Length {
+			t.Fatalf("Expected length %d, got %d (%q)", opts.Length, len(password), password)
+		}
+		if countIn(password, numberBytes) != 1 || countIn(password, specialBytes) != 1 {
+			t.Fatalf("Password %q should contain exactly one digit and one symbol", password)
+		}
+
+		// Without the inserted characters the letters alternate between
+		// consonants and vowels, starting with a capitalized consonant.
+		letters := strings.ToLower(strings.Map(func(r rune) rune {
+			if strings.ContainsRune(numberBytes+specialBytes, r) {
+				return -1
+			}
+			return r
+		}, password))
+		if !strings.ContainsRune(uppercaseBytes, rune(strings.TrimLeft(password, numberBytes+specialBytes)[0])) {
+			t.Fatalf("Password %q does not start with an uppercase letter", password)
+		}
+		for j, ch := range letters {
+			want := pronounceableConsonants
+			if j%2 == 1 {
+				want = pronounceableVowels
+			}
+			if !strings.ContainsRune(want, ch) {
+				t.Fatalf("Password %q breaks the consonant/vowel pattern at letter %d", password, j)
+			}
+		}
+	}
+}
+
+func TestGeneratePronounceableTooShort(t *testing.T) {
+	_, err := GeneratePronounceable(PronounceableOptions{Length: 3, AddDigit: true, AddSymbol: true})
+	if err == nil {
+		t.Fatal("Expected error when no room is left for letters, got nil")
+	}
+}
+
+func TestGeneratePronounceableExcludesCharacters(t *testing.T) {
+	opts := PronounceableOptions{Length: 12, Capitalize: true, AddDigit: true, AddSymbol: true, Exclude: "aeiBD2#", NoAmbiguous: true}
+
+	for i := 0; i < 100; i++ {
+		password, err := GeneratePronounceable(opts)
+		if err != nil {
+			t.Fatalf("GeneratePronounceable failed: %v", err)
+		}
+		if strings.ContainsAny(password, opts.Exclude+ambiguousBytes) {
+			t.Fatalf("Password %q contains an excluded character", password)
+		}
+	}
+
+	// l is ambiguous and B and D rule out b and d as the capitalized first
+	// letter; three vowels, three digits and one symbol are gone as well
+	want := math.Log2(13) + 4*math.Log2(15) + 5*math.Log2(2) + math.Log2(7*6) + math.Log2(4*7)
+	if got := PronounceableEntropy(opts); math.Abs(got-want) > 1e-9 {
+		t.Fatalf("PronounceableEntropy = %f, want %f", got, want)
+	}
+
+	for _, opts := range []PronounceableOptions{
+		{Length: 8, Exclude: "aeiou"},
+		{Length: 8, Exclude: pronounceableConsonants},
+		{Length: 8, AddDigit: true, Exclude: numberBytes},
+		{Length: 8, AddSymbol: true, Symbols: "%", Exclude: "%"},
+	} {
+		if _, err := GeneratePronounceable(opts); err == nil {
+			t.Fatalf("GeneratePronounceable(%+v) should fail", opts)
+		}
+	}
+}
+
+func TestCustomSymbolsAreCheckedAndDeduplicated(t *testing.T) {
+	for _, symbols := range []string{"%é", "% ", "%\t"} {
+		if _, err := GeneratePassphrase(PassphraseOptions{Words: 4, AddSymbol: true, Symbols: symbols}); err == nil {
+			t.Fatalf("GeneratePassphrase accepted symbols %q", symbols)
+		}
+		if _, err := GeneratePronounceable(PronounceableOptions{Length: 8, AddSymbol: true, Symbols: symbols}); err == nil {
+			t.Fatalf("GeneratePronounceable accepted symbols %q", symbols)
+		}
+	}
+
+	// A repeated symbol does not count twice
+	if got, want := PassphraseEntropy(PassphraseOptions{Words: 4, AddSymbol: true, Symbols: "%%%*"}),
+		PassphraseEntropy(PassphraseOptions{Words: 4, AddSymbol: true, Symbols: "%*"}); got != want {
+		t.Fatalf("PassphraseEntropy with repeated symbols = %f, want %f", got, want)
+	}
+	if got, want := PronounceableEntropy(PronounceableOptions{Length: 8, AddSymbol: true, Symbols: "%%%*"}),
+		PronounceableEntropy(PronounceableOptions{Length: 8, AddSymbol: true, Symbols: "%*"}); got != want {
+		t.Fatalf("PronounceableEntropy with repeated symbols = %f, want %f", got, want)
+	}
+}
+
+func TestPasswordEntropy(t *testing.T) {
+	bits, err := PasswordEntropy(PasswordOptions{Length: 10, Numbers: true})
+	if err != nil {
+		t.Fatalf("PasswordEntropy failed: %v", err)
+	}
+	// 10 characters from 36 symbols
+	if bits < 51.69 || bits > 51.70 {
+		t.Fatalf("Expected about 51.7 bits, got %f", bits)
+	}
+}
+
+func TestCharacterClasses(t *testing.T) {
+	got := strings.Join(CharacterClasses("aB3-"), ",")
+	if got != "lowercase,uppercase,digit,special" {
+		t.Fatalf("Unexpected classes %q", got)
+	}
+	if got := CharacterClasses("1234"); len(got) != 1 || got[0] != "digit" {
+		t.Fatalf("Unexpected classes %v", got)
+	}
+}
+
+// countingReader yields a fixed, repeatable byte sequence.
+type countingReader struct{ next byte }
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = r.next
+		r.next += 37
+	}
+	return len(p), nil
+}
+
+func TestGenerateWithOptionsIsDeterministicForFixedSource(t *testing.T) {
+	opts := PasswordOptions{Length: 16, Numbers: true, Uppercase: true, Special: true}
+
+	opts.Rand = &countingReader{}
+	first, err := GenerateWithOptions(opts)
+	if err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	opts.Rand = &countingReader{}
+	second, err := GenerateWithOptions(opts)
+	if err != nil {
+		t.Fatalf("GenerateWithOptions failed: %v", err)
+	}
+	if first != second {
+		t.Fatalf("Same random source produced %q and %q", first, second)
+	}
+}
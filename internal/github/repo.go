@@ -112,19 +112,43 @@ func doRequestWithRetry(buildReq func() (*http.Request, error)) ([]byte, int, er
 // CreateOrGetVaultRepo ensures the genp-vault private repo exists on the user's GitHub account.
 // If it already exists, it returns the existing repo info. Otherwise, it creates a new one.
 func CreateOrGetVaultRepo(token string) (*RepoInfo, error) {
+	return CreateOrGetRepo(token, vaultRepoName)
+}
+
+// CreateOrGetRepo ensures the named private repo exists on the user's GitHub
+// account. An empty name means the genp-vault repo.
+func CreateOrGetRepo(token string, repoName string) (*RepoInfo, error) {
+	if repoName == "" {
+		repoName = vaultRepoName
+	}
+
 	// First, check if the repo already exists
-	repo, err := getRepo(token, vaultRepoName)
+	repo, err := getRepo(token, repoName)
 	if err == nil {
 		return repo, nil
 	}
 
 	// Repo doesn't exist, create it
-	return createRepo(token, vaultRepoName)
+	return createRepo(token, repoName)
 }
 
 // SyncConfigToVault pushes the local genp.yaml file to the genp-vault GitHub repo.
 // It handles both creating and updating the file.
 func SyncConfigToVault(configPath string) error {
+	return SyncConfigToRepo(configPath, vaultRepoName, vaultFileName)
+}
+
+// SyncConfigToRepo pushes a local vault file to filePath in the named private
+// repo, creating the repo if needed. Empty names mean the genp-vault repo and
+// genp.yaml.
+func SyncConfigToRepo(configPath string, repoName string, filePath string) error {
+	if repoName == "" {
+		repoName = vaultRepoName
+	}
+	if filePath == "" {
+		filePath = vaultFileName
+	}
+
 	tokenInfo, err := LoadToken()
 	if err != nil {
 		// Not logged in, skip sync silently
@@ -138,13 +162,13 @@ func SyncConfigToVault(configPath string) error {
 	}
 
 	// Ensure the vault repo exists
-	_, err = CreateOrGetVaultRepo(tokenInfo.Token)
+	_, err = CreateOrGetRepo(tokenInfo.Token, repoName)
 	if err != nil {
 		return fmt.Errorf("failed to ensure vault repo exists: %w", err)
 	}
 
 	// Push the file to the repo
-	return pushFile(tokenInfo.Token, tokenInfo.Username, vaultRepoName, vaultFileName, data)
+	return pushFile(tokenInfo.Token, tokenInfo.Username, repoName, filePath, data)
 }
 
 // SyncConfigToVaultIfLoggedIn is a convenience wrapper that only syncs if the user
@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"fmt"
+	"math"
+	"strings"
+)
+
+//go:embed wordlist.txt
+var wordlistData string
+
+// maxPassphraseWords bounds the size of a generated passphrase.
+const maxPassphraseWords = 256
+
+// wordlist holds 1296 (6^4) short, distinct English words so a passphrase
+// can also be rolled with four dice per word.
+var wordlist = strings.Fields(wordlistData)
+
+// PassphraseOptions configures GeneratePassphrase.
+type PassphraseOptions struct {
+	Words      int    // number of words, at least 1
+	Separator  string // placed between words
+	Capitalize bool   // upper-case the first letter of every word
+	AddDigit   bool   // append a random digit to a random word
+	AddSymbol  bool   // append a random special character to a random word
+	Symbols    string // characters used by AddSymbol, defaults to !@#$&
+}
+
+// GeneratePassphrase builds a diceware-style passphrase from the embedded wordlist.
+func GeneratePassphrase(opts PassphraseOptions) (string, error) {
+	if opts.Words < 1 || opts.Words > maxPassphraseWords {
+		return "", fmt.Errorf("passphrase must contain between 1 and %d words", maxPassphraseWords)
+	}
+	symbols, err := symbolSet(opts.Symbols)
+	if err != nil {
+		return "", err
+	}
+
+	words := make([]string, opts.Words)
+	for i := range words {
+		idx, err := randomIndex(rand.Reader, len(wordlist))
+		if err != nil {
+			return "", fmt.Errorf("failed to pick word: %w", err)
+		}
+		word := wordlist[idx]
+		if opts.Capitalize {
+			word = strings.ToUpper(word[:1]) + word[1:]
+		}
+		words[i] = word
+	}
+
+	if opts.AddDigit {
+		if err := appendRandomChar(words, numberBytes); err != nil {
+			return "", fmt.Errorf("failed to add digit: %w", err)
+		}
+	}
+	if opts.AddSymbol {
+		if err := appendRandomChar(words, symbols); err != nil {
+			return "", fmt.Errorf("failed to add symbol: %w", err)
+		}
+	}
+
+	return strings.Join(words, opts.Separator), nil
+}
+
+// Classes returns the character classes of a passphrase generated with
+// opts, in the order of CharacterClasses. The separator is not counted.
+func (o PassphraseOptions) Classes() []string {
+	classes := []string{"lowercase"}
+	if o.Capitalize {
+		classes = append(classes, "uppercase")
+	}
+	if o.AddDigit {
+		classes = append(classes, "digit")
+	}
+	if o.AddSymbol {
+		classes = append(classes, "special")
+	}
+	return classes
+}
+
+// PassphraseEntropy returns the entropy in bits of a passphrase generated
+// with opts, assuming the attacker knows the wordlist and the options used.
+func PassphraseEntropy(opts PassphraseOptions) float64 {
+	symbols, err := symbolSet(opts.Symbols)
+	if opts.Words < 1 || err != nil {
+		return 0
+	}
+	bits := float64(opts.Words) * math.Log2(float64(len(wordlist)))
+	if opts.AddDigit {
+		bits += math.Log2(float64(len(numberBytes) * opts.Words))
+	}
+	if opts.AddSymbol {
+		bits += math.Log2(float64(len(symbols) * opts.Words))
+	}
+	return bits
+}
+
+// appendRandomChar appends one random character from charset to a randomly
+// chosen word.
+func appendRandomChar(words []string, charset string) error {
+	wordIdx, err := randomIndex(rand.Reader, len(words))
+	if err != nil {
+		return err
+	}
+	charIdx, err := randomIndex(rand.Reader, len(charset))
+	if err != nil {
+		return err
+	}
+	words[wordIdx] += string(charset[charIdx])
+	return nil
+}
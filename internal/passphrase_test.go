@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGeneratePassphrase(t *testing.T) {
+	tests := []struct {
+		name string
+		opts PassphraseOptions
+	}{
+		{"plain", PassphraseOptions{Words: 6, Separator: "-"}},
+		{"one word", PassphraseOptions{Words: 1, Separator: "-"}},
+		{"space separator", PassphraseOptions{Words: 4, Separator: " "}},
+		{"long separator", PassphraseOptions{Words: 5, Separator: "::"}},
+		{"capitalized", PassphraseOptions{Words: 6, Separator: ".", Capitalize: true}},
+		{"digit", PassphraseOptions{Words: 4, Separator: "-", AddDigit: true}},
+		{"symbol", PassphraseOptions{Words: 4, Separator: " ", AddSymbol: true}},
+		{"custom symbols", PassphraseOptions{Words: 3, Separator: " ", AddSymbol: true, Symbols: "%*"}},
+		{"everything", PassphraseOptions{Words: 8, Separator: "_", Capitalize: true, AddDigit: true, AddSymbol: true}},
+	}
+	for _, tt := range tests {
+		symbols := tt.opts.Symbols
+		if symbols == "" {
+			symbols = specialBytes
+		}
+		for i := 0; i < 50; i++ {
+			passphrase, err := GeneratePassphrase(tt.opts)
+			if err != nil {
+				t.Fatalf("%s: GeneratePassphrase failed: %v", tt.name, err)
+			}
+
+			words := strings.Split(passphrase, tt.opts.Separator)
+			if len(words) != tt.opts.Words {
+				t.Fatalf("%s: %q has %d words, want %d", tt.name, passphrase, len(words), tt.opts.Words)
+			}
+			digits, specials := 0, 0
+			for _, word := range words {
+				if tt.opts.Capitalize != strings.ContainsAny(word[:1], uppercaseBytes) {
+					t.Fatalf("%s: word %q of %q is not capitalized as requested", tt.name, word, passphrase)
+				}
+				// Digits and symbols are only ever appended to a word
+				bare := strings.TrimRight(word, numberBytes+symbols)
+				digits += countIn(word[len(bare):], numberBytes)
+				specials += countIn(word[len(bare):], symbols)
+				if !slices.Contains(wordlist, strings.ToLower(bare)) {
+					t.Fatalf("%s: %q is not from the wordlist", tt.name, bare)
+				}
+			}
+			if want := boolCount(tt.opts.AddDigit); digits != want {
+				t.Fatalf("%s: %q has %d digits, want %d", tt.name, passphrase, digits, want)
+			}
+			if want := boolCount(tt.opts.AddSymbol); specials != want {
+				t.Fatalf("%s: %q has %d symbols, want %d", tt.name, passphrase, specials, want)
+			}
+		}
+	}
+}
+
+func TestGeneratePassphraseWordCount(t *testing.T) {
+	for _, words := range []int{0, -1, maxPassphraseWords + 1} {
+		if _, err := GeneratePassphrase(PassphraseOptions{Words: words}); err == nil {
+			t.Fatalf("GeneratePassphrase accepted %d words", words)
+		}
+	}
+	if _, err := GeneratePassphrase(PassphraseOptions{Words: maxPassphraseWords}); err != nil {
+		t.Fatalf("GeneratePassphrase with %d words failed: %v", maxPassphraseWords, err)
+	}
+}
+
+func TestPassphraseEntropy(t *testing.T) {
+	perWord := math.Log2(1296)
+	tests := []struct {
+		opts PassphraseOptions
+		want float64
+	}{
+		{PassphraseOptions{Words: 0}, 0},
+		{PassphraseOptions{Words: 1}, perWord},
+		{PassphraseOptions{Words: 6, Separator: " ", Capitalize: true}, 6 * perWord},
+		{PassphraseOptions{Words: 4, AddDigit: true}, 4*perWord + math.Log2(10*4)},
+		{PassphraseOptions{Words: 4, AddSymbol: true}, 4*perWord + math.Log2(5*4)},
+		{PassphraseOptions{Words: 5, AddDigit: true, AddSymbol: true, Symbols: "%*+"}, 5*perWord + math.Log2(10*5) + math.Log2(3*5)},
+	}
+	for _, tt := range tests {
+		if got := PassphraseEntropy(tt.opts); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("PassphraseEntropy(%+v) = %f, want %f", tt.opts, got, tt.want)
+		}
+	}
+	if len(wordlist) != 1296 {
+		t.Fatalf("The wordlist has %d words, want 6^4 = 1296", len(wordlist))
+	}
+}
+
+func TestPassphraseClasses(t *testing.T) {
+	tests := []struct {
+		opts PassphraseOptions
+		want []string
+	}{
+		{PassphraseOptions{Words: 4, Separator: "-"}, []string{"lowercase"}},
+		{PassphraseOptions{Words: 4, Separator: "-", Capitalize: true, AddDigit: true}, []string{"lowercase", "uppercase", "digit"}},
+		{PassphraseOptions{Words: 4, Separator: " ", AddSymbol: true}, []string{"lowercase", "special"}},
+	}
+	for _, tt := range tests {
+		if got := tt.opts.Classes(); !slices.Equal(got, tt.want) {
+			t.Errorf("Classes(%+v) = %q, want %q", tt.opts, got, tt.want)
+		}
+		// The options produce exactly these classes, the separator aside
+		passphrase, err := GeneratePassphrase(tt.opts)
+		if err != nil {
+			t.Fatalf("GeneratePassphrase failed: %v", err)
+		}
+		if got := CharacterClasses(strings.ReplaceAll(passphrase, tt.opts.Separator, "")); !slices.Equal(got, tt.want) {
+			t.Errorf("%q has classes %q, want %q", passphrase, got, tt.want)
+		}
+	}
+}
+
+// boolCount returns 1 for true and 0 for false.
+func boolCount(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
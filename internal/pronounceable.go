@@ -0,0 +1,191 @@
+package internal
+
+import (
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// pronounceableConsonants leaves out c, q, w, x and y, whose sound is
+	// easily confused when spelled out loud.
+	pronounceableConsonants = "bdfghjklmnprstvz"
+	pronounceableVowels     = "aeiou"
+)
+
+// PronounceableOptions configures GeneratePronounceable.
+type PronounceableOptions struct {
+	Length     int    // total number of characters, including any digit or symbol
+	Capitalize bool   // upper-case the first letter
+	AddDigit   bool   // insert a random digit between two syllables
+	AddSymbol  bool   // insert a random special character between two syllables
+	Symbols    string // characters used by AddSymbol, defaults to !@#$&
+
+	Exclude     string // characters that must never appear
+	NoAmbiguous bool   // drop look-alike characters such as 0O1lI
+}
+
+// pronounceableSets holds the characters a pronounceable password is drawn
+// from once excluded characters are removed.
+type pronounceableSets struct {
+	first      string // consonants for the first letter
+	consonants string
+	vowels     string
+	digits     string
+	symbols    string
+}
+
+// sets returns the character sets of opts without excluded characters. It
+// fails if a set that is needed has no characters left.
+func (o PronounceableOptions) sets() (pronounceableSets, error) {
+	drop := o.Exclude
+	if o.NoAmbiguous {
+		drop += ambiguousBytes
+	}
+	symbols, err := symbolSet(o.Symbols)
+	if err != nil {
+		return pronounceableSets{}, err
+	}
+
+	sets := pronounceableSets{
+		consonants: filterChars(pronounceableConsonants, drop),
+		vowels:     filterChars(pronounceableVowels, drop),
+		digits:     filterChars(numberBytes, drop),
+		symbols:    filterChars(symbols, drop),
+	}
+	sets.first = sets.consonants
+	if o.Capitalize {
+		// The first letter is upper-cased, so its capital must not be
+		// excluded either
+		sets.first = filterChars(sets.consonants, strings.ToLower(drop))
+	}
+
+	switch {
+	case sets.consonants == "" || sets.first == "":
+		return pronounceableSets{}, errors.New("every consonant is excluded")
+	case sets.vowels == "":
+		return pronounceableSets{}, errors.New("every vowel is excluded")
+	case o.AddDigit && sets.digits == "":
+		return pronounceableSets{}, errors.New("every digit character is excluded")
+	case o.AddSymbol && sets.symbols == "":
+		return pronounceableSets{}, errors.New("every special character is excluded")
+	}
+	return sets, nil
+}
+
+// letters returns how many of the Length characters are letters.
+func (o PronounceableOptions) letters() int {
+	n := o.Length
+	if o.AddDigit {
+		n--
+	}
+	if o.AddSymbol {
+		n--
+	}
+	return n
+}
+
+// GeneratePronounceable builds a password of alternating consonants and
+// vowels ("bafutemo") that is easy to read aloud. Digits and symbols are
+// only ever placed between syllables so the words stay intact.
+func GeneratePronounceable(opts PronounceableOptions) (string, error) {
+	if opts.Length > MaxPasswordLength {
+		return "", ErrInvalidLength
+	}
+	letters := opts.letters()
+	if letters < 2 {
+		return "", errors.New("pronounceable password must contain at least two letters")
+	}
+	sets, err := opts.sets()
+	if err != nil {
+		return "", err
+	}
+
+	var parts []string
+	for i := 0; i < letters; i += 2 {
+		consonants := sets.consonants
+		if i == 0 {
+			consonants = sets.first
+		}
+		consonant, err := randomIndex(rand.Reader, len(consonants))
+		if err != nil {
+			return "", fmt.Errorf("failed to pick consonant: %w", err)
+		}
+		syllable := string(consonants[consonant])
+		if i+1 < letters {
+			vowel, err := randomIndex(rand.Reader, len(sets.vowels))
+			if err != nil {
+				return "", fmt.Errorf("failed to pick vowel: %w", err)
+			}
+			syllable += string(sets.vowels[vowel])
+		}
+		parts = append(parts, syllable)
+	}
+
+	if opts.Capitalize {
+		parts[0] = strings.ToUpper(parts[0][:1]) + parts[0][1:]
+	}
+
+	if opts.AddDigit {
+		if parts, err = insertRandomChar(parts, sets.digits); err != nil {
+			return "", fmt.Errorf("failed to add digit: %w", err)
+		}
+	}
+	if opts.AddSymbol {
+		if parts, err = insertRandomChar(parts, sets.symbols); err != nil {
+			return "", fmt.Errorf("failed to add symbol: %w", err)
+		}
+	}
+
+	return strings.Join(parts, ""), nil
+}
+
+// PronounceableEntropy returns the entropy in bits of a password generated
+// with opts, assuming the attacker knows the scheme and the options used.
+func PronounceableEntropy(opts PronounceableOptions) float64 {
+	letters := opts.letters()
+	if letters < 2 {
+		return 0
+	}
+	sets, err := opts.sets()
+	if err != nil {
+		return 0
+	}
+
+	consonants := (letters + 1) / 2
+	vowels := letters / 2
+	bits := math.Log2(float64(len(sets.first))) +
+		float64(consonants-1)*math.Log2(float64(len(sets.consonants))) +
+		float64(vowels)*math.Log2(float64(len(sets.vowels)))
+
+	// Each inserted character can go before, between or after the parts
+	slots := consonants + 1
+	if opts.AddDigit {
+		bits += math.Log2(float64(len(sets.digits) * slots))
+		slots++
+	}
+	if opts.AddSymbol {
+		bits += math.Log2(float64(len(sets.symbols) * slots))
+	}
+	return bits
+}
+
+// insertRandomChar inserts one random character from charset as a new
+// part at a random position of parts.
+func insertRandomChar(parts []string, charset string) ([]string, error) {
+	pos, err := randomIndex(rand.Reader, len(parts)+1)
+	if err != nil {
+		return nil, err
+	}
+	charIdx, err := randomIndex(rand.Reader, len(charset))
+	if err != nil {
+		return nil, err
+	}
+
+	parts = append(parts, "")
+	copy(parts[pos+1:], parts[pos:])
+	parts[pos] = string(charset[charIdx])
+	return parts, nil
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrRandomSource is returned when the random number generator fails.
+// No password is produced in that case, never a degraded one.
+var ErrRandomSource = errors.New("random number generator failed")
+
+// randomIndex returns a uniformly distributed integer in [0, n) read from r.
+// It uses rejection sampling so that no index is favoured when n does not
+// divide 2^32 evenly.
+func randomIndex(r io.Reader, n int) (int, error) {
+	if n <= 0 {
+		return 0, errors.New("random index range must be positive")
+	}
+
+	limit := uint64(1<<32) - uint64(1<<32)%uint64(n)
+	var buf [4]byte
+	for {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return 0, fmt.Errorf("%w: %v", ErrRandomSource, err)
+		}
+		v := uint64(binary.BigEndian.Uint32(buf[:]))
+		if v < limit {
+			return int(v % uint64(n)), nil
+		}
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// backupSuffix is appended to the config path for the copy of the previous
+// version kept by writeFileAtomic.
+const backupSuffix = ".bak"
+
+// File operations used by writeFileAtomic. Tests replace them to simulate
+// full disks and crashes in the middle of a write.
+var (
+	writeTemp  = func(f *os.File, data []byte) (int, error) { return f.Write(data) }
+	syncFile   = func(f *os.File) error { return f.Sync() }
+	renameFile = os.Rename
+)
+
+// writeFileAtomic replaces the file at path with data so that a crash or a
+// full disk never leaves a truncated file behind: data is written to a
+// temporary file in the same directory, flushed to disk and renamed over
+// path. The previous content is kept in path + ".bak".
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	previous, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := replaceFile(path+backupSuffix, previous, perm); err != nil {
+			return fmt.Errorf("failed to write backup: %w", err)
+		}
+	case !errors.Is(err, os.ErrNotExist):
+		return err
+	}
+
+	return replaceFile(path, data, perm)
+}
+
+// replaceFile writes data to a temporary file next to path and renames it
+// over path once it is safely on disk. On failure path is left untouched
+// and the temporary file is removed.
+func replaceFile(path string, data []byte, perm os.FileMode) (err error) {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	defer func() {
+		if err != nil {
+			tmp.Close()
+			os.Remove(tmpPath)
+		}
+	}()
+
+	if err = tmp.Chmod(perm); err != nil {
+		return err
+	}
+	n, err := writeTemp(tmp, data)
+	if err == nil && n < len(data) {
+		err = fmt.Errorf("short write: %d of %d bytes", n, len(data))
+	}
+	if err != nil {
+		return err
+	}
+	if err = syncFile(tmp); err != nil {
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+	if err = renameFile(tmpPath, path); err != nil {
+		return err
+	}
+
+	// Flush the rename itself; not every platform can sync a directory
+	if d, dirErr := os.Open(dir); dirErr == nil {
+		d.Sync()
+		d.Close()
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// failWrites makes writeFileAtomic fail at one of its steps for the rest
+// of the test.
+func failWrites(t *testing.T, step string) {
+	t.Helper()
+	origWrite, origSync, origRename := writeTemp, syncFile, renameFile
+	t.Cleanup(func() { writeTemp, syncFile, renameFile = origWrite, origSync, origRename })
+
+	switch step {
+	case "write":
+		// Only half of the data reaches the disk before it fills up
+		writeTemp = func(f *os.File, data []byte) (int, error) {
+			n, _ := f.Write(data[:len(data)/2])
+			return n, syscall.ENOSPC
+		}
+	case "short":
+		writeTemp = func(f *os.File, data []byte) (int, error) {
+			return f.Write(data[:len(data)/2])
+		}
+	case "sync":
+		syncFile = func(*os.File) error { return syscall.EIO }
+	case "rename":
+		renameFile = func(string, string) error { return syscall.EIO }
+	}
+}
+
+func TestWriteFileAtomicKeepsOriginalOnFailure(t *testing.T) {
+	for _, step := range []string{"write", "short", "sync", "rename"} {
+		t.Run(step, func(t *testing.T) {
+			dir := t.TempDir()
+			path := filepath.Join(dir, "genp.yaml")
+			if err := os.WriteFile(path, []byte("entries: {}\n"), 0o600); err != nil {
+				t.Fatalf("Failed to write config: %v", err)
+			}
+
+			failWrites(t, step)
+			if err := writeFileAtomic(path, []byte("entries:\n    github:\n        password: c2VjcmV0\n"), 0o600); err == nil {
+				t.Fatal("writeFileAtomic should report the failed write")
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil || string(data) != "entries: {}\n" {
+				t.Fatalf("Original file was damaged: %q, %v", data, err)
+			}
+			files, _ := os.ReadDir(dir)
+			for _, f := range files {
+				if f.Name() != "genp.yaml" && f.Name() != "genp.yaml.bak" {
+					t.Fatalf("Temporary file %s was left behind", f.Name())
+				}
+			}
+		})
+	}
+}
+
+func TestWriteFileAtomicKeepsBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "genp.yaml")
+
+	for _, content := range []string{"first\n", "second\n", "third\n"} {
+		if err := writeFileAtomic(path, []byte(content), 0o600); err != nil {
+			t.Fatalf("writeFileAtomic failed: %v", err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "third\n" {
+		t.Fatalf("Unexpected content %q, %v", data, err)
+	}
+	backup, err := os.ReadFile(path + backupSuffix)
+	if err != nil || string(backup) != "second\n" {
+		t.Fatalf("Backup should hold the previous version, got %q, %v", backup, err)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("Stat failed: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Fatalf("Expected permissions 0600, got %o", perm)
+	}
+}
+
+func TestLoadConfigFileRepairSurvivesFailedWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "genp.yaml")
+	legacy := "password:\n  github: b2xk\npassword:\n  github: bmV3\n"
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	failWrites(t, "write")
+	cfg, err := loadConfigFile(path)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if got := cfg.Entries["github"]; got == nil || got.Password != "bmV3" {
+		t.Fatalf("Expected the migrated entry, got %+v", got)
+	}
+
+	// The interrupted repair leaves the legacy file readable as it was
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != legacy {
+		t.Fatalf("Legacy file was damaged by the failed repair: %q, %v", data, err)
+	}
+}
+
+func TestLoadConfigFileRepairKeepsLegacyBackup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "genp.yaml")
+	legacy := "password:\n    github: c2VjcmV0MQ==\n"
+	if err := os.WriteFile(path, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	if _, err := loadConfigFile(path); err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	backup, err := os.ReadFile(path + backupSuffix)
+	if err != nil || string(backup) != legacy {
+		t.Fatalf("Legacy file should be kept as backup, got %q, %v", backup, err)
+	}
+	if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+		t.Fatal("Repaired config is missing")
+	}
+}
@@ -6,6 +6,7 @@ Copyright © 2026 @mdxabu
 package store
 
 import (
+	"errors"
 	"os"
 	"runtime"
 	"strings"
@@ -15,6 +16,9 @@ import (
 )
 
 func TestE2EEIntegration(t *testing.T) {
+	// Keep the stored entries out of the real config directory
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
 	// Clean up any existing test config
 	OSName := runtime.GOOS
 	baseDir, err := ConfigBaseDir("genp-test", OSName)
@@ -120,3 +124,218 @@ func TestE2EEIntegration(t *testing.T) {
 		}
 	})
 }
+
+func TestEntryEncryptsSensitiveFields(t *testing.T) {
+	masterPassword := "TestMasterPassword123!"
+	details := EntryDetails{
+		Password: "MySecretPassword456!",
+		Username: "alice@example.com",
+		URLs:     []string{"https://example.com"},
+		Notes:    "recovery codes in the safe",
+		Tags:     []string{"work"},
+	}
+
+	key, err := NewVaultKey(masterPassword)
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+
+	entry, err := NewEntry("github", details, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+	for _, field := range []string{entry.Password, entry.Username, entry.Notes} {
+		if strings.Contains(field, "alice") || strings.Contains(field, "Secret") || strings.Contains(field, "recovery") {
+			t.Fatalf("Sensitive field stored in plaintext: %q", field)
+		}
+	}
+	if entry.Created.IsZero() || entry.Updated.IsZero() {
+		t.Fatal("Expected created and updated timestamps to be set")
+	}
+
+	decrypted, err := entry.Decrypt(key)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	if decrypted.Password != details.Password || decrypted.Username != details.Username || decrypted.Notes != details.Notes {
+		t.Fatalf("Decrypted entry doesn't match. Got %+v, want %+v", decrypted, details)
+	}
+
+	// Optional fields stay empty instead of failing to encrypt
+	bare, err := NewEntry("github", EntryDetails{Password: "only-a-password"}, key)
+	if err != nil {
+		t.Fatalf("NewEntry without details failed: %v", err)
+	}
+	if bare.Username != "" || bare.Notes != "" {
+		t.Fatalf("Expected empty optional fields, got %+v", bare)
+	}
+}
+
+func TestEntryUpdateRecordsPasswordChanges(t *testing.T) {
+	key, err := NewVaultKey("TestMasterPassword123!")
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+	entry, err := NewEntry("github", EntryDetails{Password: "first", Username: "alice"}, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+
+	// Editing other fields leaves the history alone
+	if err := entry.Update(EntryDetails{Password: "first", Username: "bob"}, key); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if len(entry.History) != 0 {
+		t.Fatalf("Unchanged password should not be added to the history: %+v", entry.History)
+	}
+
+	if err := entry.Update(EntryDetails{Password: "second"}, key); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	previous, err := entry.VersionPassword(1, key)
+	if err != nil || previous != "first" {
+		t.Fatalf("Expected the previous password in the history, got %q, %v", previous, err)
+	}
+}
+
+func TestEntryCiphertextsAreBoundToNameAndField(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	key, err := NewVaultKey("TestMasterPassword123!")
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+	for name, password := range map[string]string{"github": "one", "gitlab": "two"} {
+		entry, err := NewEntry(name, EntryDetails{Password: password, Username: "alice"}, key)
+		if err != nil {
+			t.Fatalf("NewEntry failed: %v", err)
+		}
+		if _, err := StoreEntry(name, entry, "linux"); err != nil {
+			t.Fatalf("StoreEntry(%q) failed: %v", name, err)
+		}
+	}
+	github, _ := GetEntry("github")
+	gitlab, _ := GetEntry("gitlab")
+
+	// A password copied to another entry or field no longer decrypts
+	swapped := *gitlab
+	swapped.Password = github.Password
+	if _, err := swapped.Field(FieldPassword, key); err == nil {
+		t.Fatal("A password moved to another entry decrypted")
+	}
+	swapped = *github
+	swapped.Username = github.Password
+	if _, err := swapped.Field(FieldUsername, key); err == nil {
+		t.Fatal("A password moved to the username field decrypted")
+	}
+
+	// An entry encrypted for one name cannot be stored under another
+	if _, err := StoreEntry("bitbucket", github, "linux"); err == nil {
+		t.Fatal("StoreEntry accepted an entry encrypted for another name")
+	}
+
+	// Renaming re-encrypts the entry for its new name
+	if _, err := RenameEntry("github", "work/github", key, "linux"); err != nil {
+		t.Fatalf("RenameEntry failed: %v", err)
+	}
+	renamed, err := GetEntry("work/github")
+	if err != nil {
+		t.Fatalf("GetEntry failed: %v", err)
+	}
+	details, err := renamed.Decrypt(key)
+	if err != nil || details.Password != "one" || details.Username != "alice" {
+		t.Fatalf("Renamed entry decrypts to %+v, %v", details, err)
+	}
+}
+
+func TestEntryField(t *testing.T) {
+	key, err := NewVaultKey("TestMasterPassword123!")
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+	entry, err := NewEntry("github", EntryDetails{
+		Password: "s3cret",
+		Username: "alice",
+		URLs:     []string{"https://github.com", "https://gist.github.com"},
+		Notes:    "2FA on the phone",
+	}, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+
+	tests := []struct {
+		field string
+		want  string
+	}{
+		{FieldPassword, "s3cret"},
+		{FieldUsername, "alice"},
+		{FieldURL, "https://github.com\nhttps://gist.github.com"},
+		{FieldNotes, "2FA on the phone"},
+	}
+	for _, tt := range tests {
+		if got, err := entry.Field(tt.field, key); err != nil || got != tt.want {
+			t.Errorf("Field(%s) = %q, %v; want %q", tt.field, got, err, tt.want)
+		}
+	}
+
+	// URLs are not encrypted and need no key
+	if got, err := entry.Field(FieldURL, nil); err != nil || got != tests[2].want {
+		t.Errorf("Field(url) without a key = %q, %v", got, err)
+	}
+	// Fields that were never set read back empty
+	bare, _ := NewEntry("gitlab", EntryDetails{Password: "only"}, key)
+	for _, field := range []string{FieldUsername, FieldURL, FieldNotes} {
+		if got, err := bare.Field(field, key); err != nil || got != "" {
+			t.Errorf("Field(%s) of an entry without it = %q, %v", field, got, err)
+		}
+	}
+
+	if _, err := entry.Field("email", key); err == nil || !strings.Contains(err.Error(), `unknown field "email"`) {
+		t.Fatalf("Expected an unknown field error, got %v", err)
+	}
+	other, _ := NewVaultKey("TestMasterPassword123!")
+	if _, err := entry.Field(FieldPassword, other); err == nil {
+		t.Fatal("Field decrypted with another vault key")
+	}
+}
+
+func TestEntryVersion(t *testing.T) {
+	key, err := NewVaultKey("TestMasterPassword123!")
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+	entry, err := NewEntry("github", EntryDetails{Password: "first"}, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+	if _, err := entry.Version(1); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("Version(1) of an entry without history: %v", err)
+	}
+	for _, password := range []string{"second", "third"} {
+		if err := entry.Update(EntryDetails{Password: password}, key); err != nil {
+			t.Fatalf("Update failed: %v", err)
+		}
+	}
+
+	// Version 1 is the password replaced most recently
+	for version, want := range map[int]string{1: "second", 2: "first"} {
+		item, err := entry.Version(version)
+		if err != nil {
+			t.Fatalf("Version(%d) failed: %v", version, err)
+		}
+		if item.Password != entry.History[len(entry.History)-version].Password {
+			t.Fatalf("Version(%d) returned the wrong item", version)
+		}
+		if got, err := entry.VersionPassword(version, key); err != nil || got != want {
+			t.Errorf("VersionPassword(%d) = %q, %v; want %q", version, got, err, want)
+		}
+	}
+	for _, version := range []int{0, -1, 3} {
+		if _, err := entry.Version(version); !errors.Is(err, ErrVersionNotFound) {
+			t.Errorf("Version(%d): expected ErrVersionNotFound, got %v", version, err)
+		}
+		if _, err := entry.VersionPassword(version, key); !errors.Is(err, ErrVersionNotFound) {
+			t.Errorf("VersionPassword(%d): expected ErrVersionNotFound, got %v", version, err)
+		}
+	}
+}
@@ -0,0 +1,367 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+// Entry is a stored credential. Password, Username and Notes hold
+// ciphertexts encrypted with the vault key (see VaultKey); URLs and Tags stay in plain text
+// so entries can be listed and filtered without the master password.
+//
+// Ciphertexts encrypted with the vault key are bound to the entry name and
+// field, so they cannot be swapped between entries or fields in the file.
+// Renaming an entry therefore re-encrypts it (see RenameEntry).
+type Entry struct {
+	Password string    `yaml:"password"`
+	Username string    `yaml:"username,omitempty"`
+	URLs     []string  `yaml:"urls,omitempty"`
+	Notes    string    `yaml:"notes,omitempty"`
+	Tags     []string  `yaml:"tags,omitempty"`
+	Created  time.Time `yaml:"created,omitempty"`
+	Updated  time.Time `yaml:"updated,omitempty"`
+	// History holds earlier versions of the entry, oldest first.
+	History []HistoryItem `yaml:"history,omitempty"`
+
+	// name is the name the ciphertexts are bound to. It is set when the
+	// entry is read from or stored in a vault.
+	name string
+}
+
+// HistoryItem is an earlier version of an entry, kept encrypted so an
+// overwrite can be undone.
+type HistoryItem struct {
+	Password  string    `yaml:"password"`
+	ChangedAt time.Time `yaml:"changed_at"`
+	// Entry holds the other fields of the replaced entry. Items written
+	// before genp kept them only have the password.
+	Entry *EntrySnapshot `yaml:"entry,omitempty"`
+}
+
+// EntrySnapshot is the state of an entry apart from its password and
+// history. Username and Notes stay encrypted as in Entry.
+type EntrySnapshot struct {
+	Username string    `yaml:"username,omitempty"`
+	URLs     []string  `yaml:"urls,omitempty"`
+	Notes    string    `yaml:"notes,omitempty"`
+	Tags     []string  `yaml:"tags,omitempty"`
+	Updated  time.Time `yaml:"updated,omitempty"`
+}
+
+// Names of the entry fields that can be read on their own with Entry.Field.
+const (
+	FieldPassword = "password"
+	FieldUsername = "username"
+	FieldURL      = "url"
+	FieldNotes    = "notes"
+)
+
+// EntryDetails holds the decrypted fields of an entry.
+type EntryDetails struct {
+	Password string
+	Username string
+	URLs     []string
+	Notes    string
+	Tags     []string
+}
+
+// NewEntry encrypts the sensitive fields of details with the vault key for
+// the entry called name and stamps the creation time.
+func NewEntry(name string, details EntryDetails, key *VaultKey) (*Entry, error) {
+	now := time.Now().UTC()
+	entry := &Entry{
+		name:    name,
+		URLs:    details.URLs,
+		Tags:    details.Tags,
+		Created: now,
+		Updated: now,
+	}
+
+	var err error
+	if entry.Password, err = key.encrypt(details.Password, entry.fieldAD(FieldPassword)); err != nil {
+		return nil, fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	if entry.Username, err = entry.encryptOptional(FieldUsername, details.Username, key); err != nil {
+		return nil, fmt.Errorf("failed to encrypt username: %w", err)
+	}
+	if entry.Notes, err = entry.encryptOptional(FieldNotes, details.Notes, key); err != nil {
+		return nil, fmt.Errorf("failed to encrypt notes: %w", err)
+	}
+
+	return entry, nil
+}
+
+// Update re-encrypts the entry with new details and stamps the update time.
+// The creation time and the history are kept; if the password changes, the
+// old version is added to the history.
+func (e *Entry) Update(details EntryDetails, key *VaultKey) error {
+	current, err := key.decrypt(e.Password, e.fieldAD(FieldPassword))
+	if err != nil {
+		return err
+	}
+
+	updated, err := NewEntry(e.name, details, key)
+	if err != nil {
+		return err
+	}
+	if current == details.Password {
+		updated.Created = e.Created
+		updated.History = e.History
+	} else {
+		updated.supersede(e)
+	}
+	*e = *updated
+	return nil
+}
+
+// supersede makes e the successor of old: old is appended to the history
+// it carries over, and its creation time is kept.
+func (e *Entry) supersede(old *Entry) {
+	history := make([]HistoryItem, 0, len(old.History)+1)
+	history = append(history, old.History...)
+	e.History = append(history, old.historyItem())
+	if !old.Created.IsZero() {
+		e.Created = old.Created
+	}
+}
+
+// historyItem returns the current state of e as a history item.
+func (e *Entry) historyItem() HistoryItem {
+	return HistoryItem{
+		Password:  e.Password,
+		ChangedAt: time.Now().UTC(),
+		Entry: &EntrySnapshot{
+			Username: e.Username,
+			URLs:     e.URLs,
+			Notes:    e.Notes,
+			Tags:     e.Tags,
+			Updated:  e.Updated,
+		},
+	}
+}
+
+// sameVersion reports whether e and other are the same version of an
+// entry: every change stamps a new update time or moves ciphertexts.
+func (e *Entry) sameVersion(other *Entry) bool {
+	return e.Updated.Equal(other.Updated) &&
+		e.Password == other.Password &&
+		e.Username == other.Username &&
+		e.Notes == other.Notes &&
+		slices.Equal(e.URLs, other.URLs) &&
+		slices.Equal(e.Tags, other.Tags) &&
+		len(e.History) == len(other.History)
+}
+
+// Version returns an earlier password of the entry. Versions are numbered
+// from 1, the password replaced most recently, as listed by 'genp history'.
+func (e *Entry) Version(version int) (HistoryItem, error) {
+	if version < 1 || version > len(e.History) {
+		return HistoryItem{}, fmt.Errorf("%w: %d (the entry has %d)", ErrVersionNotFound, version, len(e.History))
+	}
+	return e.History[len(e.History)-version], nil
+}
+
+// VersionPassword returns an earlier password of the entry, decrypted.
+// Versions are numbered as by Version.
+func (e *Entry) VersionPassword(version int, key *VaultKey) (string, error) {
+	item, err := e.Version(version)
+	if err != nil {
+		return "", err
+	}
+	return key.decrypt(item.Password, e.fieldAD(FieldPassword))
+}
+
+// restore makes an earlier version current again, or only its password
+// for items that have nothing else. The current version takes its place in
+// the history, so a restore can itself be undone.
+func (e *Entry) restore(version int) error {
+	item, err := e.Version(version)
+	if err != nil {
+		return err
+	}
+
+	idx := len(e.History) - version
+	history := make([]HistoryItem, 0, len(e.History))
+	history = append(history, e.History[:idx]...)
+	history = append(history, e.History[idx+1:]...)
+	e.History = append(history, e.historyItem())
+	e.Password = item.Password
+	if item.Entry != nil {
+		e.Username, e.URLs, e.Notes, e.Tags = item.Entry.Username, item.Entry.URLs, item.Entry.Notes, item.Entry.Tags
+	}
+	e.Updated = time.Now().UTC()
+	return nil
+}
+
+// pruneHistory drops the oldest history items beyond retention.
+func (e *Entry) pruneHistory(retention int) {
+	if len(e.History) > retention {
+		e.History = e.History[len(e.History)-retention:]
+	}
+}
+
+// Decrypt returns the plaintext fields of the entry.
+func (e *Entry) Decrypt(key *VaultKey) (EntryDetails, error) {
+	details := EntryDetails{URLs: e.URLs, Tags: e.Tags}
+
+	var err error
+	if details.Password, err = key.decrypt(e.Password, e.fieldAD(FieldPassword)); err != nil {
+		return EntryDetails{}, err
+	}
+	if details.Username, err = e.decryptOptional(FieldUsername, e.Username, key); err != nil {
+		return EntryDetails{}, err
+	}
+	if details.Notes, err = e.decryptOptional(FieldNotes, e.Notes, key); err != nil {
+		return EntryDetails{}, err
+	}
+
+	return details, nil
+}
+
+// Field returns a single decrypted field of the entry, leaving the other
+// ciphertexts untouched. URLs are returned one per line and need no key.
+func (e *Entry) Field(field string, key *VaultKey) (string, error) {
+	switch field {
+	case FieldPassword:
+		return key.decrypt(e.Password, e.fieldAD(FieldPassword))
+	case FieldUsername:
+		return e.decryptOptional(FieldUsername, e.Username, key)
+	case FieldURL:
+		return strings.Join(e.URLs, "\n"), nil
+	case FieldNotes:
+		return e.decryptOptional(FieldNotes, e.Notes, key)
+	default:
+		return "", fmt.Errorf("unknown field %q (use %s, %s, %s or %s)", field, FieldPassword, FieldUsername, FieldURL, FieldNotes)
+	}
+}
+
+// ciphertext is an encrypted field of an entry or of one of its earlier
+// versions.
+type ciphertext struct {
+	field string
+	value *string
+}
+
+// ciphertexts returns every encrypted field of the entry, including its
+// history. Earlier versions use the field names of the current one.
+func (e *Entry) ciphertexts() []ciphertext {
+	fields := []ciphertext{{FieldPassword, &e.Password}, {FieldUsername, &e.Username}, {FieldNotes, &e.Notes}}
+	for i := range e.History {
+		fields = append(fields, ciphertext{FieldPassword, &e.History[i].Password})
+		if snapshot := e.History[i].Entry; snapshot != nil {
+			fields = append(fields, ciphertext{FieldUsername, &snapshot.Username}, ciphertext{FieldNotes, &snapshot.Notes})
+		}
+	}
+	return fields
+}
+
+// reencrypt re-encrypts the ciphertexts of the entry, including its
+// history, that are not encrypted with the vault key yet. Without a vault
+// key every ciphertext is re-encrypted with the master password and
+// params. It reports whether anything changed; nothing is changed unless
+// all of them decrypt.
+func (e *Entry) reencrypt(key *VaultKey, params crypto.KDFParams) (bool, error) {
+	fields := e.ciphertexts()
+	reencrypted := make([]string, len(fields))
+	changed := false
+	for i, field := range fields {
+		reencrypted[i] = *field.value
+		if *field.value == "" || (key.dek != nil && crypto.UsesKey(*field.value)) {
+			continue
+		}
+		ad := e.fieldAD(field.field)
+		plaintext, err := key.decrypt(*field.value, ad)
+		if err != nil {
+			return false, err
+		}
+		if key.dek != nil {
+			reencrypted[i], err = crypto.EncryptWithKey(plaintext, key.dek, ad)
+		} else {
+			reencrypted[i], err = crypto.EncryptWithKDF(plaintext, key.masterPassword, params)
+		}
+		if err != nil {
+			return false, err
+		}
+		changed = true
+	}
+	for i, field := range fields {
+		*field.value = reencrypted[i]
+	}
+	return changed, nil
+}
+
+// rebind binds the ciphertexts of the entry to a new name, re-encrypting
+// those encrypted with the vault key. Nothing is changed unless all of
+// them decrypt.
+func (e *Entry) rebind(name string, key *VaultKey) error {
+	fields := e.ciphertexts()
+	rebound := make([]string, len(fields))
+	for i, field := range fields {
+		rebound[i] = *field.value
+		if !crypto.UsesKey(*field.value) {
+			continue
+		}
+		plaintext, err := key.decrypt(*field.value, e.fieldAD(field.field))
+		if err != nil {
+			return err
+		}
+		if rebound[i], err = key.encrypt(plaintext, fieldAD(name, field.field)); err != nil {
+			return err
+		}
+	}
+	for i, field := range fields {
+		*field.value = rebound[i]
+	}
+	e.name = name
+	return nil
+}
+
+// bind sets the name the entry is stored under. An entry encrypted for
+// another name cannot be stored under this one, since its ciphertexts
+// would no longer decrypt.
+func (e *Entry) bind(name string) error {
+	if e.name != "" && e.name != name {
+		return fmt.Errorf("entry %q was encrypted for %q", name, e.name)
+	}
+	e.name = name
+	return nil
+}
+
+// fieldAD returns the additional data binding a ciphertext to a field of
+// the entry.
+func (e *Entry) fieldAD(field string) []byte {
+	return fieldAD(e.name, field)
+}
+
+// fieldAD returns the additional data binding a ciphertext to a field of
+// the entry called name. Field names never contain a NUL byte, so the
+// encoding is unambiguous whatever the entry name.
+func fieldAD(name string, field string) []byte {
+	return []byte("genp-entry\x00" + name + "\x00" + field)
+}
+
+// encryptOptional encrypts plaintext for field, leaving empty fields empty.
+func (e *Entry) encryptOptional(field string, plaintext string, key *VaultKey) (string, error) {
+	if plaintext == "" {
+		return "", nil
+	}
+	return key.encrypt(plaintext, e.fieldAD(field))
+}
+
+// decryptOptional decrypts the ciphertext of field, leaving empty fields
+// empty.
+func (e *Entry) decryptOptional(field string, ciphertext string, key *VaultKey) (string, error) {
+	if ciphertext == "" {
+		return "", nil
+	}
+	return key.decrypt(ciphertext, e.fieldAD(field))
+}
@@ -0,0 +1,120 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// FolderSeparator separates the folders of a path-style entry name such as
+// work/aws/prod.
+const FolderSeparator = "/"
+
+// Filter selects entries by folder and tags. The zero Filter matches every
+// entry.
+type Filter struct {
+	// Folder matches entries inside it at any depth, e.g. "work" matches
+	// work/github and work/aws/prod but not workshop.
+	Folder string
+	// Tags must all be present on an entry; case is ignored.
+	Tags []string
+}
+
+// Match reports whether the named entry passes the filter.
+func (f Filter) Match(name string, entry *Entry) bool {
+	if folder := CleanFolder(f.Folder); folder != "" && !strings.HasPrefix(name, folder+FolderSeparator) {
+		return false
+	}
+	for _, want := range f.Tags {
+		if !hasTag(entry, want) {
+			return false
+		}
+	}
+	return true
+}
+
+// Names returns the sorted names of the entries that pass the filter.
+func (f Filter) Names(entries map[string]*Entry) []string {
+	names := make([]string, 0, len(entries))
+	for name, entry := range entries {
+		if f.Match(name, entry) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CleanFolder strips the separators around a folder name.
+func CleanFolder(folder string) string {
+	return strings.Trim(folder, FolderSeparator)
+}
+
+// ValidateEntryName rejects names that cannot be shown as a folder path,
+// such as "work//aws" or "work/".
+func ValidateEntryName(name string) error {
+	if name == "" {
+		return errors.New("entry name must not be empty")
+	}
+	for _, part := range strings.Split(name, FolderSeparator) {
+		if strings.TrimSpace(part) == "" {
+			return fmt.Errorf("invalid entry name %q: folders and names must not be empty", name)
+		}
+	}
+	return nil
+}
+
+// FindEntry resolves name to a stored entry that passes the filter. An exact
+// name wins; name is also tried inside the filter's folder, and a plain
+// name such as "prod" matches an entry like work/aws/prod if exactly one
+// entry with that last element passes the filter. It returns the full name.
+func FindEntry(name string, filter Filter) (string, *Entry, error) {
+	entries, err := loadEntries()
+	if err != nil {
+		return "", nil, err
+	}
+
+	candidates := []string{name}
+	if folder := CleanFolder(filter.Folder); folder != "" {
+		candidates = append(candidates, folder+FolderSeparator+name)
+	}
+	for _, candidate := range candidates {
+		if entry, ok := entries[candidate]; ok && filter.Match(candidate, entry) {
+			return candidate, entry, nil
+		}
+	}
+
+	if !strings.Contains(name, FolderSeparator) {
+		var matches []string
+		for _, candidate := range filter.Names(entries) {
+			if strings.HasSuffix(candidate, FolderSeparator+name) {
+				matches = append(matches, candidate)
+			}
+		}
+		switch len(matches) {
+		case 1:
+			return matches[0], entries[matches[0]], nil
+		case 0:
+		default:
+			return "", nil, fmt.Errorf("%q is ambiguous, it matches %s", name, strings.Join(matches, ", "))
+		}
+	}
+
+	return "", nil, fmt.Errorf("%w: %q", ErrEntryNotFound, name)
+}
+
+// hasTag reports whether entry carries tag, ignoring case.
+func hasTag(entry *Entry, tag string) bool {
+	for _, t := range entry.Tags {
+		if strings.EqualFold(t, tag) {
+			return true
+		}
+	}
+	return false
+}
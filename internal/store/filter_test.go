@@ -0,0 +1,98 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func testEntries() map[string]*Entry {
+	return map[string]*Entry{
+		"work/aws/prod":    {Tags: []string{"prod", "root"}},
+		"work/aws/staging": {},
+		"work/github":      {Tags: []string{"Prod"}},
+		"workshop":         {},
+		"personal/mail":    {},
+		"a/dup":            {},
+		"b/dup":            {},
+	}
+}
+
+func TestFilterNames(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []string
+	}{
+		{"folder", Filter{Folder: "work"}, []string{"work/aws/prod", "work/aws/staging", "work/github"}},
+		{"nested folder with slashes", Filter{Folder: "/work/aws/"}, []string{"work/aws/prod", "work/aws/staging"}},
+		{"tag ignores case", Filter{Tags: []string{"PROD"}}, []string{"work/aws/prod", "work/github"}},
+		{"all tags required", Filter{Tags: []string{"prod", "root"}}, []string{"work/aws/prod"}},
+		{"folder and tag", Filter{Folder: "work/aws", Tags: []string{"prod"}}, []string{"work/aws/prod"}},
+		{"no match", Filter{Folder: "nothing"}, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Names(testEntries()); !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("Names = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateEntryName(t *testing.T) {
+	for _, name := range []string{"github", "work/aws/prod"} {
+		if err := ValidateEntryName(name); err != nil {
+			t.Errorf("ValidateEntryName(%q) failed: %v", name, err)
+		}
+	}
+	for _, name := range []string{"", "work/", "/work", "work//aws", "work/ /aws"} {
+		if err := ValidateEntryName(name); err == nil {
+			t.Errorf("ValidateEntryName(%q) should fail", name)
+		}
+	}
+}
+
+func TestFindEntry(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("the active vault is only redirected through XDG_CONFIG_HOME on Linux and BSD")
+	}
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for name, entry := range testEntries() {
+		entry.Password = "c2VjcmV0"
+		if _, err := StoreEntry(name, entry, runtime.GOOS); err != nil {
+			t.Fatalf("StoreEntry(%q) failed: %v", name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		filter Filter
+		want   string
+	}{
+		{"workshop", Filter{}, "workshop"},
+		{"work/github", Filter{}, "work/github"},
+		{"staging", Filter{}, "work/aws/staging"},
+		{"prod", Filter{Folder: "work/aws"}, "work/aws/prod"},
+		{"dup", Filter{Folder: "b"}, "b/dup"},
+	}
+	for _, tt := range tests {
+		got, _, err := FindEntry(tt.name, tt.filter)
+		if err != nil || got != tt.want {
+			t.Errorf("FindEntry(%q, %+v) = %q, %v; want %q", tt.name, tt.filter, got, err, tt.want)
+		}
+	}
+
+	if _, _, err := FindEntry("dup", Filter{}); err == nil {
+		t.Error("FindEntry should report an ambiguous name")
+	}
+	if _, _, err := FindEntry("workshop", Filter{Tags: []string{"prod"}}); !errors.Is(err, ErrEntryNotFound) {
+		t.Errorf("An entry outside the filter should not be found, got %v", err)
+	}
+}
@@ -14,15 +14,71 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/mdxabu/genp/internal/config"
 	"github.com/mdxabu/genp/internal/crypto"
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	// ErrEntryNotFound is returned when no entry has the requested name.
+	ErrEntryNotFound = errors.New("no entry found with name")
+	// ErrEntryExists is returned when storing under a name that is taken.
+	ErrEntryExists = errors.New("an entry already exists with name")
+	// ErrVersionNotFound is returned for a history version an entry lacks.
+	ErrVersionNotFound = errors.New("no such version")
+	// ErrEntryChanged is returned when an entry was changed by another
+	// command while it was being edited.
+	ErrEntryChanged = errors.New("entry was changed by another command since it was read")
+)
+
+// DefaultHistoryRetention is the number of earlier passwords kept per entry
+// unless settings.history_retention says otherwise.
+const DefaultHistoryRetention = 10
+
 // ConfigFile represents the top-level structure of genp.yaml
 type ConfigFile struct {
-	Password map[string]string `yaml:"password"`
+	// Version is the file format version, see CurrentVersion. Older files
+	// are upgraded when they are loaded.
+	Version  int               `yaml:"version"`
+	Vault    VaultHeader       `yaml:"vault,omitempty"`
+	Entries  map[string]*Entry `yaml:"entries"`
+	Policies map[string]Policy `yaml:"policies,omitempty"`
+	Settings Settings          `yaml:"settings,omitempty"`
+}
+
+// Settings holds user preferences stored in genp.yaml.
+type Settings struct {
+	// HistoryRetention is the number of earlier passwords kept per entry.
+	// Zero means DefaultHistoryRetention.
+	HistoryRetention int `yaml:"history_retention,omitempty"`
+	// KDF is the key derivation used for new ciphertexts in this vault.
+	// Empty means crypto.DefaultArgon2idParams; see 'genp rekey'.
+	KDF crypto.KDFParams `yaml:"kdf,omitempty"`
+	// OSVerify also asks for the system password, verified against the
+	// operating system, before the master password. It is only checked,
+	// never used as a key.
+	OSVerify bool `yaml:"os_verify,omitempty"`
+	// Auth selects how the user is verified before the vault is unlocked.
+	// It replaces OSVerify; see ConfigFile.authenticator.
+	Auth crypto.AuthConfig `yaml:"auth,omitempty"`
+}
+
+// kdf returns the configured KDF or the one in use by crypto.Encrypt.
+func (s Settings) kdf() crypto.KDFParams {
+	if s.KDF.Algorithm != "" {
+		return s.KDF
+	}
+	return crypto.DefaultKDF()
+}
+
+// historyRetention returns the configured retention or the default.
+func (s Settings) historyRetention() int {
+	if s.HistoryRetention > 0 {
+		return s.HistoryRetention
+	}
+	return DefaultHistoryRetention
 }
 
 // StoreLocalConfig creates a cross-platform config directory and writes a credentials file
@@ -35,10 +91,183 @@ type ConfigFile struct {
 // The file uses proper YAML marshaling to avoid duplicate key issues.
 
 func StoreLocalConfig(passwordName string, password string, osName string) (string, error) {
-	if passwordName == "" {
-		return "", errors.New("passwordName must not be empty")
+	now := time.Now().UTC()
+	return StoreEntry(passwordName, &Entry{Password: password, Created: now, Updated: now}, osName)
+}
+
+// StoreEntry adds the named entry to genp.yaml. The entry's sensitive
+// fields must already be encrypted (see NewEntry). It never overwrites an
+// existing entry: a taken name returns an error wrapping ErrEntryExists.
+func StoreEntry(passwordName string, entry *Entry, osName string) (string, error) {
+	if err := ValidateEntryName(passwordName); err != nil {
+		return "", err
+	}
+
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		if _, exists := cfg.Entries[passwordName]; exists {
+			return fmt.Errorf("%w: %q", ErrEntryExists, passwordName)
+		}
+		if err := entry.bind(passwordName); err != nil {
+			return err
+		}
+		cfg.Entries[passwordName] = entry
+		return nil
+	})
+}
+
+// ReplaceEntry stores entry under passwordName, overwriting any existing
+// entry. The previous entry is kept in the new one's history so the
+// overwrite can be undone.
+func ReplaceEntry(passwordName string, entry *Entry, osName string) (string, error) {
+	if err := ValidateEntryName(passwordName); err != nil {
+		return "", err
+	}
+
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		if err := entry.bind(passwordName); err != nil {
+			return err
+		}
+		if old, exists := cfg.Entries[passwordName]; exists {
+			entry.supersede(old)
+			entry.pruneHistory(cfg.Settings.historyRetention())
+		}
+		cfg.Entries[passwordName] = entry
+		return nil
+	})
+}
+
+// AvailableName returns name if no entry uses it yet, or else the first free
+// name of the form name-2, name-3, ...
+func AvailableName(name string) (string, error) {
+	confPath, err := GetConfigFilePath()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine config file path: %w", err)
+	}
+
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		return "", err
+	}
+
+	candidate := name
+	for i := 2; ; i++ {
+		if _, exists := cfg.Entries[candidate]; !exists {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s-%d", name, i)
+	}
+}
+
+// UpdateEntry replaces an existing entry, e.g. after editing its fields.
+// read is the entry as it was before editing. If the stored entry no longer
+// matches it, because it was restored, edited or replaced in the meantime,
+// nothing is written and an error wrapping ErrEntryChanged is returned.
+func UpdateEntry(name string, read *Entry, entry *Entry, osName string) (string, error) {
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		stored, ok := cfg.Entries[name]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrEntryNotFound, name)
+		}
+		if !stored.sameVersion(read) {
+			return fmt.Errorf("%w: %q", ErrEntryChanged, name)
+		}
+		if err := entry.bind(name); err != nil {
+			return err
+		}
+		entry.pruneHistory(cfg.Settings.historyRetention())
+		cfg.Entries[name] = entry
+		return nil
+	})
+}
+
+// RestoreEntry makes an earlier version of the named entry current again,
+// moving the current one into the history. Versions are numbered as by
+// Entry.Version. No master password is needed since only ciphertexts move.
+func RestoreEntry(name string, version int, osName string) (string, error) {
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		entry, ok := cfg.Entries[name]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrEntryNotFound, name)
+		}
+		return entry.restore(version)
+	})
+}
+
+// DeleteEntry removes the named entry from genp.yaml.
+func DeleteEntry(name string, osName string) (string, error) {
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		if _, ok := cfg.Entries[name]; !ok {
+			return fmt.Errorf("%w: %q", ErrEntryNotFound, name)
+		}
+		delete(cfg.Entries, name)
+		return nil
+	})
+}
+
+// RenameEntry moves an entry to a new name. It refuses to replace an
+// existing entry. The entry's ciphertexts are bound to its name, so they
+// are re-encrypted for the new one with key.
+func RenameEntry(oldName string, newName string, key *VaultKey, osName string) (string, error) {
+	if err := ValidateEntryName(newName); err != nil {
+		return "", err
+	}
+
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		entry, ok := cfg.Entries[oldName]
+		if !ok {
+			return fmt.Errorf("%w: %q", ErrEntryNotFound, oldName)
+		}
+		if _, exists := cfg.Entries[newName]; exists {
+			return fmt.Errorf("%w: %q", ErrEntryExists, newName)
+		}
+		if err := entry.rebind(newName, key); err != nil {
+			return fmt.Errorf("failed to re-encrypt %q: %w", oldName, err)
+		}
+		delete(cfg.Entries, oldName)
+		cfg.Entries[newName] = entry
+		return nil
+	})
+}
+
+// updateConfig loads the active vault, applies fn and writes the result
+// back while holding the config lock, so concurrent genp processes cannot
+// lose each other's changes. Nothing is written if fn returns an error.
+func updateConfig(osName string, fn func(cfg *ConfigFile) error) (string, error) {
+	confPath, err := localConfigPath(osName)
+	if err != nil {
+		return "", err
 	}
+	return confPath, updateConfigAt(confPath, fn)
+}
+
+// updateConfigAt is updateConfig for the config file at confPath.
+func updateConfigAt(confPath string, fn func(cfg *ConfigFile) error) error {
+	return withConfigLock(confPath, func() error {
+		// Load existing config or create a new one
+		cfg, _, err := readConfigFile(confPath)
+		if err != nil {
+			return fmt.Errorf("failed to load existing config: %w", err)
+		}
+
+		if err := fn(cfg); err != nil {
+			return err
+		}
+
+		return saveConfigFile(confPath, cfg)
+	})
+}
 
+// localConfigPath ensures the per-OS config directory exists and returns
+// the path of the active vault inside it: genp.yaml for the default vault,
+// vaults/<name>.yaml for a named one.
+func localConfigPath(osName string) (string, error) {
+	return vaultPath(osName, activeVault)
+}
+
+// vaultPath ensures the per-OS config directory exists and returns the
+// path of the named vault inside it. Named vaults must have been created
+// with CreateVault.
+func vaultPath(osName string, vaultName string) (string, error) {
 	baseDir, err := ConfigBaseDir("genp", osName)
 	if err != nil {
 		return "", err
@@ -50,86 +279,119 @@ func StoreLocalConfig(passwordName string, password string, osName string) (stri
 		return "", fmt.Errorf("failed to create config directory %s: %w", baseDir, err)
 	}
 
-	confPath := filepath.Join(baseDir, "genp.yaml")
-
-	// Load existing config or create a new one
-	cfg, err := loadConfigFile(confPath)
-	if err != nil {
-		return "", fmt.Errorf("failed to load existing config: %w", err)
+	if vaultName == DefaultVault {
+		return filepath.Join(baseDir, "genp.yaml"), nil
 	}
 
-	// Add or update the password entry
-	cfg.Password[passwordName] = password
+	confPath := filepath.Join(baseDir, config.VaultsDirName, vaultName+config.VaultFileExt)
+	if _, err := os.Stat(confPath); err != nil {
+		if os.IsNotExist(err) {
+			return "", fmt.Errorf("%w: %q (create it with 'genp vault create %s')", ErrVaultNotFound, vaultName, vaultName)
+		}
+		return "", err
+	}
+	return confPath, nil
+}
 
-	// Marshal back to YAML
+// saveConfigFile marshals cfg back to YAML and writes it atomically with
+// restrictive permissions, keeping the previous version in genp.yaml.bak.
+func saveConfigFile(confPath string, cfg *ConfigFile) error {
+	cfg.Version = CurrentVersion
 	data, err := yaml.Marshal(cfg)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal config to YAML: %w", err)
+		return fmt.Errorf("failed to marshal config to YAML: %w", err)
 	}
 
-	// Write file with restrictive permissions
-	if err := os.WriteFile(confPath, data, 0o600); err != nil {
-		return "", fmt.Errorf("failed to write config file %s: %w", confPath, err)
+	if err := writeFileAtomic(confPath, data, 0o600); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", confPath, err)
 	}
 
-	return confPath, nil
+	return nil
 }
 
 // loadConfigFile reads and parses the genp.yaml file.
 // If the file does not exist, it returns a new empty config.
-// If the file has duplicate YAML keys (from older versions of genp),
-// it falls back to a line-based dedup parser that keeps the last value for each key.
+// Files written by older versions of genp are upgraded (see readConfigFile)
+// and repaired on disk so future reads don't hit the legacy path.
 func loadConfigFile(confPath string) (*ConfigFile, error) {
+	cfg, migrated, err := readConfigFile(confPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// The repair is a read-modify-write of its own, so it re-reads the file
+	// under the lock in case another process changed it meanwhile. The
+	// original file is kept in genp.yaml.bak; if the repair fails it is
+	// simply retried on the next load.
+	if migrated {
+		_ = withConfigLock(confPath, func() error {
+			current, migrated, err := readConfigFile(confPath)
+			if err != nil || !migrated {
+				return err
+			}
+			return saveConfigFile(confPath, current)
+		})
+	}
+
+	return cfg, nil
+}
+
+// readConfigFile reads and parses genp.yaml without writing to it.
+// Files in an older format are upgraded in memory through the migration
+// registry (see migrate.go), and it reports whether that happened. Files
+// from a newer genp are refused with ErrNewerVersion.
+func readConfigFile(confPath string) (*ConfigFile, bool, error) {
 	cfg := &ConfigFile{
-		Password: make(map[string]string),
+		Version: CurrentVersion,
+		Entries: make(map[string]*Entry),
 	}
 
 	data, err := os.ReadFile(confPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return cfg, nil
+			return cfg, false, nil
 		}
-		return nil, fmt.Errorf("failed to read config file %s: %w", confPath, err)
+		return nil, false, fmt.Errorf("failed to read config file %s: %w", confPath, err)
 	}
 
 	if len(data) == 0 {
-		return cfg, nil
+		return cfg, false, nil
 	}
 
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		// If standard YAML parsing fails (e.g. duplicate keys from old genp versions),
-		// fall back to a line-based dedup parser that keeps the last value per key.
-		dedupedCfg, dedupErr := parseDuplicateKeyYAML(data)
-		if dedupErr != nil {
-			// Return the original YAML error if fallback also fails
-			return nil, fmt.Errorf("failed to parse config file %s: %w", confPath, err)
-		}
-
-		// Repair the file on disk so future reads don't hit this path
-		repaired, marshalErr := yaml.Marshal(dedupedCfg)
-		if marshalErr == nil {
-			_ = os.WriteFile(confPath, repaired, 0o600)
+	version, err := fileVersion(data)
+	if err != nil {
+		return nil, false, fmt.Errorf("%s: %w", confPath, err)
+	}
+	migrated := version < CurrentVersion
+	if migrated {
+		if data, err = upgradeConfig(data, version); err != nil {
+			return nil, false, fmt.Errorf("failed to upgrade config file %s: %w", confPath, err)
 		}
+	}
 
-		return dedupedCfg, nil
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, false, fmt.Errorf("failed to parse config file %s: %w", confPath, err)
 	}
 
-	// Ensure the map is initialized even if YAML had no password entries
-	if cfg.Password == nil {
-		cfg.Password = make(map[string]string)
+	// Ensure the map is initialized even if YAML had no entries
+	if cfg.Entries == nil {
+		cfg.Entries = make(map[string]*Entry)
+	}
+	for name, entry := range cfg.Entries {
+		if entry != nil {
+			entry.name = name
+		}
 	}
 
-	return cfg, nil
+	return cfg, migrated, nil
 }
 
 // parseDuplicateKeyYAML handles YAML files that have duplicate mapping keys
 // (produced by older versions of genp that used string concatenation).
-// It parses line-by-line under the "password:" section and keeps the last
-// value for each key, deduplicating them.
-func parseDuplicateKeyYAML(data []byte) (*ConfigFile, error) {
-	cfg := &ConfigFile{
-		Password: make(map[string]string),
-	}
+// It parses line-by-line under the "password:" section and returns the
+// name -> ciphertext map, keeping the last value for each duplicated key.
+func parseDuplicateKeyYAML(data []byte) (map[string]string, error) {
+	passwords := make(map[string]string)
 
 	scanner := bufio.NewScanner(bytes.NewReader(data))
 	inPasswordSection := false
@@ -165,7 +427,7 @@ func parseDuplicateKeyYAML(data []byte) (*ConfigFile, error) {
 				val = strings.Trim(val, "\"")
 				if key != "" && val != "" {
 					// Last value wins for duplicate keys
-					cfg.Password[key] = val
+					passwords[key] = val
 				}
 			}
 		}
@@ -175,13 +437,25 @@ func parseDuplicateKeyYAML(data []byte) (*ConfigFile, error) {
 		return nil, fmt.Errorf("failed to scan config file: %w", err)
 	}
 
-	return cfg, nil
+	return passwords, nil
 }
 
-// GetConfigFilePath returns the full path to genp.yaml for the current OS
+// GetConfigFilePath returns the full path to the file of the active vault
+// for the current OS: genp.yaml unless a named vault was selected.
 func GetConfigFilePath() (string, error) {
 	osName := runtime.GOOS
-	return config.ConfigFilePath(osName)
+	if activeVault == DefaultVault {
+		return config.ConfigFilePath(osName)
+	}
+
+	confPath, err := config.VaultFilePath(osName, activeVault)
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(confPath); os.IsNotExist(err) {
+		return "", fmt.Errorf("%w: %q (create it with 'genp vault create %s')", ErrVaultNotFound, activeVault, activeVault)
+	}
+	return confPath, nil
 }
 
 // ConfigBaseDir determines the per-OS base config directory.
@@ -191,15 +465,8 @@ func ConfigBaseDir(appName string, osName string) (string, error) {
 	return config.BaseDirForApp(appName, osName)
 }
 
-// PasswordEntry represents a stored password entry
-type PasswordEntry struct {
-	Name      string
-	Encrypted string
-}
-
-// GetAllPasswords reads all stored passwords from the config file
-// Returns a map of password names to their encrypted values
-func GetAllPasswords() (map[string]string, error) {
+// GetAllEntries reads all stored entries from the config file.
+func GetAllEntries() (map[string]*Entry, error) {
 	confPath, err := GetConfigFilePath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to determine config file path: %w", err)
@@ -215,14 +482,60 @@ func GetAllPasswords() (map[string]string, error) {
 		return nil, err
 	}
 
-	if len(cfg.Password) == 0 {
+	if len(cfg.Entries) == 0 {
 		return nil, fmt.Errorf("no passwords found in config file")
 	}
 
-	return cfg.Password, nil
+	return cfg.Entries, nil
+}
+
+// GetEntry returns the named entry, or an error wrapping ErrEntryNotFound.
+func GetEntry(name string) (*Entry, error) {
+	entries, err := loadEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := entries[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrEntryNotFound, name)
+	}
+	return entry, nil
+}
+
+// loadEntries reads the entries of the active vault. A vault that does not
+// exist yet has no entries.
+func loadEntries() (map[string]*Entry, error) {
+	confPath, err := GetConfigFilePath()
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine config file path: %w", err)
+	}
+
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		return nil, err
+	}
+	return cfg.Entries, nil
+}
+
+// GetAllPasswords reads all stored passwords from the config file
+// Returns a map of password names to their encrypted values
+func GetAllPasswords() (map[string]string, error) {
+	entries, err := GetAllEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	passwords := make(map[string]string, len(entries))
+	for name, entry := range entries {
+		passwords[name] = entry.Password
+	}
+	return passwords, nil
 }
 
-// DecryptPassword decrypts a single password using the master password
+// DecryptPassword decrypts a single password using the master password.
+// Entries of an unlocked vault are decrypted with VaultKey.Decrypt instead,
+// since they are encrypted with the vault key.
 func DecryptPassword(encryptedPassword string, masterPassword string) (string, error) {
 	return crypto.Decrypt(encryptedPassword, masterPassword)
 }
@@ -0,0 +1,248 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfigFileMigratesLegacyPasswords(t *testing.T) {
+	confPath := filepath.Join(t.TempDir(), "genp.yaml")
+	legacy := "password:\n    github: c2VjcmV0MQ==\n    gitlab: c2VjcmV0Mg==\n"
+	if err := os.WriteFile(confPath, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if len(cfg.Entries) != 2 || cfg.Entries["github"].Password != "c2VjcmV0MQ==" {
+		t.Fatalf("Legacy passwords were not migrated: %+v", cfg.Entries)
+	}
+	if cfg.Version != CurrentVersion {
+		t.Fatalf("Legacy config should be upgraded to version %d, got %d", CurrentVersion, cfg.Version)
+	}
+
+	// The file on disk is rewritten in the new layout
+	data, err := os.ReadFile(confPath)
+	if err != nil {
+		t.Fatalf("Failed to read config: %v", err)
+	}
+	if !strings.Contains(string(data), "entries:") || strings.Contains(string(data), "\npassword:") {
+		t.Fatalf("Config was not rewritten with entries:\n%s", data)
+	}
+}
+
+func TestLoadConfigFileMigratesDuplicateKeys(t *testing.T) {
+	confPath := filepath.Join(t.TempDir(), "genp.yaml")
+	legacy := "password:\n  github: b2xk\npassword:\n  github: bmV3\n"
+	if err := os.WriteFile(confPath, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("Failed to write legacy config: %v", err)
+	}
+
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if got := cfg.Entries["github"]; got == nil || got.Password != "bmV3" {
+		t.Fatalf("Expected last duplicate value to win, got %+v", got)
+	}
+}
+
+func TestRenameAndDeleteEntry(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	for _, name := range []string{"github", "gitlab"} {
+		if _, err := StoreLocalConfig(name, "c2VjcmV0", "linux"); err != nil {
+			t.Fatalf("StoreLocalConfig(%q) failed: %v", name, err)
+		}
+	}
+
+	if _, err := RenameEntry("github", "gitlab", nil, "linux"); err == nil {
+		t.Fatal("RenameEntry should refuse to replace an existing entry")
+	}
+	confPath, err := RenameEntry("github", "github-work", nil, "linux")
+	if err != nil {
+		t.Fatalf("RenameEntry failed: %v", err)
+	}
+
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if _, ok := cfg.Entries["github"]; ok || cfg.Entries["github-work"] == nil {
+		t.Fatalf("Entry was not renamed: %+v", cfg.Entries)
+	}
+
+	if _, err := DeleteEntry("gitlab", "linux"); err != nil {
+		t.Fatalf("DeleteEntry failed: %v", err)
+	}
+	if _, err := DeleteEntry("gitlab", "linux"); !errors.Is(err, ErrEntryNotFound) {
+		t.Fatalf("Deleting a missing entry should return ErrEntryNotFound, got %v", err)
+	}
+	cfg, err = loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if len(cfg.Entries) != 1 {
+		t.Fatalf("Expected one entry left, got %+v", cfg.Entries)
+	}
+}
+
+func TestStoreEntryRefusesOverwrite(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := StoreLocalConfig("github", "b2xk", "linux"); err != nil {
+		t.Fatalf("StoreLocalConfig failed: %v", err)
+	}
+	if _, err := StoreLocalConfig("github", "bmV3", "linux"); !errors.Is(err, ErrEntryExists) {
+		t.Fatalf("Storing under a taken name should return ErrEntryExists, got %v", err)
+	}
+
+	confPath, err := ReplaceEntry("github", &Entry{Password: "bmV3"}, "linux")
+	if err != nil {
+		t.Fatalf("ReplaceEntry failed: %v", err)
+	}
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	entry := cfg.Entries["github"]
+	if entry.Password != "bmV3" {
+		t.Fatalf("Entry was not replaced: %+v", entry)
+	}
+	if len(entry.History) != 1 || entry.History[0].Password != "b2xk" {
+		t.Fatalf("Previous password was not kept in the history: %+v", entry.History)
+	}
+	if entry.Created.IsZero() {
+		t.Fatal("Replacing an entry should keep its creation time")
+	}
+}
+
+func TestReplaceEntryKeepsWholeEntry(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	old := &Entry{Password: "b2xk", Username: "dXNlcg==", URLs: []string{"https://old.example"}, Notes: "bm90ZQ==", Tags: []string{"work"}}
+	if _, err := StoreEntry("github", old, "linux"); err != nil {
+		t.Fatalf("StoreEntry failed: %v", err)
+	}
+	if _, err := ReplaceEntry("github", &Entry{Password: "bmV3"}, "linux"); err != nil {
+		t.Fatalf("ReplaceEntry failed: %v", err)
+	}
+	entry, err := GetEntry("github")
+	if err != nil {
+		t.Fatalf("GetEntry failed: %v", err)
+	}
+	item, err := entry.Version(1)
+	if err != nil || item.Entry == nil || item.Entry.Username != old.Username || item.Entry.Notes != old.Notes ||
+		len(item.Entry.URLs) != 1 || len(item.Entry.Tags) != 1 {
+		t.Fatalf("The overwritten entry was not kept in the history: %+v, %v", item, err)
+	}
+
+	// Restoring undoes the overwrite completely
+	if _, err := RestoreEntry("github", 1, "linux"); err != nil {
+		t.Fatalf("RestoreEntry failed: %v", err)
+	}
+	entry, _ = GetEntry("github")
+	if entry.Password != old.Password || entry.Username != old.Username || entry.Notes != old.Notes ||
+		len(entry.URLs) != 1 || entry.URLs[0] != old.URLs[0] || len(entry.Tags) != 1 {
+		t.Fatalf("Restore did not bring the old entry back: %+v", entry)
+	}
+
+	// Items written before whole entries were kept only restore the password
+	entry.History = append(entry.History, HistoryItem{Password: "b2xkZXI="})
+	if err := entry.restore(1); err != nil {
+		t.Fatalf("restore failed: %v", err)
+	}
+	if entry.Password != "b2xkZXI=" || entry.Username != old.Username {
+		t.Fatalf("Restoring a password-only item changed other fields: %+v", entry)
+	}
+}
+
+func TestUpdateEntryRejectsConcurrentChanges(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := StoreLocalConfig("github", "b2xk", "linux"); err != nil {
+		t.Fatalf("StoreLocalConfig failed: %v", err)
+	}
+	read, err := GetEntry("github")
+	if err != nil {
+		t.Fatalf("GetEntry failed: %v", err)
+	}
+	edited := *read
+	edited.Username = "ZWRpdGVk"
+	if _, err := UpdateEntry("github", read, &edited, "linux"); err != nil {
+		t.Fatalf("UpdateEntry failed: %v", err)
+	}
+
+	// Another command replaces the entry while it is being edited again
+	read, _ = GetEntry("github")
+	edited = *read
+	edited.Notes = "bm90ZQ=="
+	if _, err := ReplaceEntry("github", &Entry{Password: "bmV3"}, "linux"); err != nil {
+		t.Fatalf("ReplaceEntry failed: %v", err)
+	}
+	if _, err := UpdateEntry("github", read, &edited, "linux"); !errors.Is(err, ErrEntryChanged) {
+		t.Fatalf("Expected ErrEntryChanged, got %v", err)
+	}
+	if entry, _ := GetEntry("github"); entry.Password != "bmV3" {
+		t.Fatalf("The concurrent change was lost: %+v", entry)
+	}
+}
+
+func TestRestoreEntryAndRetention(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	confPath, err := StoreLocalConfig("github", "djE=", "linux")
+	if err != nil {
+		t.Fatalf("StoreLocalConfig failed: %v", err)
+	}
+	for _, password := range []string{"djI=", "djM="} {
+		if _, err := ReplaceEntry("github", &Entry{Password: password}, "linux"); err != nil {
+			t.Fatalf("ReplaceEntry failed: %v", err)
+		}
+	}
+
+	// Version 1 is the password replaced most recently
+	if _, err := RestoreEntry("github", 2, "linux"); err != nil {
+		t.Fatalf("RestoreEntry failed: %v", err)
+	}
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	entry := cfg.Entries["github"]
+	if entry.Password != "djE=" {
+		t.Fatalf("Expected version 2 to be restored, got %q", entry.Password)
+	}
+	if item, err := entry.Version(1); err != nil || item.Password != "djM=" {
+		t.Fatalf("The replaced password should become version 1, got %+v, %v", item, err)
+	}
+	if _, err := RestoreEntry("github", 3, "linux"); !errors.Is(err, ErrVersionNotFound) {
+		t.Fatalf("Restoring a missing version should return ErrVersionNotFound, got %v", err)
+	}
+
+	// Older passwords beyond the retention are dropped on the next change
+	cfg.Settings.HistoryRetention = 1
+	if err := saveConfigFile(confPath, cfg); err != nil {
+		t.Fatalf("saveConfigFile failed: %v", err)
+	}
+	if _, err := ReplaceEntry("github", &Entry{Password: "djQ="}, "linux"); err != nil {
+		t.Fatalf("ReplaceEntry failed: %v", err)
+	}
+	cfg, err = loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if history := cfg.Entries["github"].History; len(history) != 1 || history[0].Password != "djE=" {
+		t.Fatalf("Expected only the latest history item to be kept, got %+v", history)
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+)
+
+// ErrLockTimeout is returned when another genp process keeps genp.yaml
+// locked for longer than the lock timeout.
+var ErrLockTimeout = errors.New("timed out waiting for the config file lock")
+
+const (
+	// lockSuffix names the file locked with flock next to genp.yaml.
+	lockSuffix = ".lock"
+	// lockfileSuffix names the file created exclusively where flock is not
+	// available. It is removed again on unlock.
+	lockfileSuffix = ".lockfile"
+	// staleLockAge is how old a fallback lockfile must be before it is
+	// treated as left behind by a crashed process. Locks are only held for
+	// a single read-modify-write, which takes milliseconds.
+	staleLockAge = 2 * time.Minute
+)
+
+var (
+	// lockTimeout bounds how long a writer waits for the lock.
+	lockTimeout = 10 * time.Second
+	// lockRetryInterval is the pause between two attempts to take the lock.
+	lockRetryInterval = 20 * time.Millisecond
+)
+
+// withConfigLock runs fn while holding an exclusive advisory lock on
+// confPath, so concurrent genp processes never interleave their
+// read-modify-write cycles.
+func withConfigLock(confPath string, fn func() error) error {
+	unlock, err := lockFile(confPath)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return fn()
+}
+
+// retryLock calls try until it takes the lock, fails or the lock timeout
+// expires. try reports false while somebody else holds the lock.
+func retryLock(confPath string, try func() (bool, error)) error {
+	deadline := time.Now().Add(lockTimeout)
+	for {
+		locked, err := try()
+		if err != nil || locked {
+			return err
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w %s after %s", ErrLockTimeout, confPath, lockTimeout)
+		}
+		time.Sleep(lockRetryInterval)
+	}
+}
+
+// lockWithLockfile takes the lock by exclusively creating confPath +
+// ".lockfile". It works on every platform and file system, at the price of
+// leaving a stale lockfile if genp crashes while holding it; such a file is
+// removed once it is older than staleLockAge.
+func lockWithLockfile(confPath string) (func(), error) {
+	path := confPath + lockfileSuffix
+	err := retryLock(confPath, func() (bool, error) {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			return true, f.Close()
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return false, fmt.Errorf("failed to create lock file %s: %w", path, err)
+		}
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > staleLockAge {
+			os.Remove(path)
+		}
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() { os.Remove(path) }, nil
+}
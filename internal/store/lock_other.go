@@ -0,0 +1,14 @@
+//go:build !(linux || darwin || freebsd || netbsd || openbsd || dragonfly)
+
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+// lockFile locks confPath with an exclusive lockfile, since flock is not
+// available on this platform.
+func lockFile(confPath string) (func(), error) {
+	return lockWithLockfile(confPath)
+}
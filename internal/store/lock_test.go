@@ -0,0 +1,140 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// writerEnv makes the test binary act as one of the writers spawned by
+// TestConcurrentWritersKeepEveryEntry.
+const writerEnv = "GENP_TEST_WRITER"
+
+func TestMain(m *testing.M) {
+	if name := os.Getenv(writerEnv); name != "" {
+		os.Exit(runWriter(name))
+	}
+	os.Exit(m.Run())
+}
+
+// runWriter stores a few entries from concurrent goroutines.
+func runWriter(name string) int {
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := StoreLocalConfig(fmt.Sprintf("%s-%d", name, i), "c2VjcmV0", "linux")
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	return 0
+}
+
+func TestConcurrentWritersKeepEveryEntry(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	const writers = 12
+	cmds := make([]*exec.Cmd, writers)
+	for i := range cmds {
+		cmd := exec.Command(os.Args[0])
+		cmd.Env = append(os.Environ(), fmt.Sprintf("%s=writer%d", writerEnv, i))
+		cmd.Stderr = os.Stderr
+		if err := cmd.Start(); err != nil {
+			t.Fatalf("Failed to start writer: %v", err)
+		}
+		cmds[i] = cmd
+	}
+	for _, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			t.Fatalf("Writer failed: %v", err)
+		}
+	}
+
+	cfg, err := loadConfigFile(filepath.Join(configHome, "genp", "genp.yaml"))
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if len(cfg.Entries) != writers*4 {
+		t.Fatalf("Expected %d entries, got %d: lost updates", writers*4, len(cfg.Entries))
+	}
+}
+
+func TestUpdateConfigLockTimeout(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+	confPath, err := localConfigPath("linux")
+	if err != nil {
+		t.Fatalf("localConfigPath failed: %v", err)
+	}
+
+	origTimeout := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = origTimeout })
+
+	unlock, err := lockFile(confPath)
+	if err != nil {
+		t.Fatalf("lockFile failed: %v", err)
+	}
+	if _, err := StoreLocalConfig("github", "c2VjcmV0", "linux"); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Expected ErrLockTimeout while the lock is held, got %v", err)
+	}
+
+	unlock()
+	if _, err := StoreLocalConfig("github", "c2VjcmV0", "linux"); err != nil {
+		t.Fatalf("StoreLocalConfig failed after unlock: %v", err)
+	}
+}
+
+func TestLockfileFallback(t *testing.T) {
+	confPath := filepath.Join(t.TempDir(), "genp.yaml")
+
+	origTimeout := lockTimeout
+	lockTimeout = 100 * time.Millisecond
+	t.Cleanup(func() { lockTimeout = origTimeout })
+
+	unlock, err := lockWithLockfile(confPath)
+	if err != nil {
+		t.Fatalf("lockWithLockfile failed: %v", err)
+	}
+	if _, err := lockWithLockfile(confPath); !errors.Is(err, ErrLockTimeout) {
+		t.Fatalf("Expected ErrLockTimeout while the lockfile exists, got %v", err)
+	}
+	unlock()
+
+	// A lockfile left behind by a crashed process expires
+	unlock, err = lockWithLockfile(confPath)
+	if err != nil {
+		t.Fatalf("lockWithLockfile failed after unlock: %v", err)
+	}
+	old := time.Now().Add(-2 * staleLockAge)
+	if err := os.Chtimes(confPath+lockfileSuffix, old, old); err != nil {
+		t.Fatalf("Chtimes failed: %v", err)
+	}
+	relock, err := lockWithLockfile(confPath)
+	if err != nil {
+		t.Fatalf("Stale lockfile was not taken over: %v", err)
+	}
+	relock()
+	unlock()
+}
@@ -0,0 +1,204 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the genp.yaml format written by this version of genp.
+//
+//   - 0: flat "password:" map of name -> ciphertext, possibly with
+//     duplicate keys (written before the version header existed)
+//   - 1: structured "entries:" with a "version:" header
+const CurrentVersion = 1
+
+// ErrNewerVersion is returned for config files written by a newer genp.
+var ErrNewerVersion = errors.New("config file was written by a newer version of genp")
+
+// migration upgrades the raw content of genp.yaml from one format version
+// to the next. apply does not need to update the version header; the
+// caller stamps it after every step.
+type migration struct {
+	from        int
+	description string
+	apply       func(data []byte) ([]byte, error)
+}
+
+// migrations holds every upgrade step, in order. Add a step here and bump
+// CurrentVersion whenever the file format changes.
+var migrations = []migration{
+	{
+		from:        0,
+		description: "move the flat password map into structured entries",
+		apply:       migrateFlatPasswords,
+	},
+}
+
+// MigrationPlan describes the steps needed to bring genp.yaml up to date.
+type MigrationPlan struct {
+	Path  string
+	From  int
+	To    int
+	Steps []string
+}
+
+// NeedsMigration reports whether the plan has any step to run.
+func (p MigrationPlan) NeedsMigration() bool {
+	return len(p.Steps) > 0
+}
+
+// PlanMigration inspects genp.yaml and lists the steps that would upgrade
+// it, without changing the file.
+func PlanMigration() (MigrationPlan, error) {
+	confPath, err := GetConfigFilePath()
+	if err != nil {
+		return MigrationPlan{}, fmt.Errorf("failed to determine config file path: %w", err)
+	}
+	return planMigration(confPath)
+}
+
+// planMigration lists the upgrade steps for the config file at confPath.
+func planMigration(confPath string) (MigrationPlan, error) {
+	data, err := os.ReadFile(confPath)
+	if err != nil {
+		return MigrationPlan{}, fmt.Errorf("failed to read config file %s: %w", confPath, err)
+	}
+
+	version, err := fileVersion(data)
+	if err != nil {
+		return MigrationPlan{}, fmt.Errorf("%s: %w", confPath, err)
+	}
+
+	plan := MigrationPlan{Path: confPath, From: version, To: CurrentVersion}
+	for _, m := range migrations {
+		if m.from >= version {
+			plan.Steps = append(plan.Steps, fmt.Sprintf("v%d -> v%d: %s", m.from, m.from+1, m.description))
+		}
+	}
+	return plan, nil
+}
+
+// Migrate upgrades genp.yaml to CurrentVersion in place, keeping the
+// original in genp.yaml.bak. It returns the plan that was carried out.
+func Migrate(osName string) (MigrationPlan, error) {
+	plan, err := PlanMigration()
+	if err != nil || !plan.NeedsMigration() {
+		return plan, err
+	}
+
+	// updateConfig reads (and so upgrades) the file under the lock and
+	// writes it back in the current format
+	if _, err := updateConfig(osName, func(*ConfigFile) error { return nil }); err != nil {
+		return plan, err
+	}
+	return plan, nil
+}
+
+// upgradeConfig runs every migration needed to bring data from version to
+// CurrentVersion.
+func upgradeConfig(data []byte, version int) ([]byte, error) {
+	for _, m := range migrations {
+		if m.from < version {
+			continue
+		}
+		var err error
+		if data, err = m.apply(data); err != nil {
+			return nil, fmt.Errorf("failed to migrate from version %d: %w", m.from, err)
+		}
+		if data, err = stampVersion(data, m.from+1); err != nil {
+			return nil, fmt.Errorf("failed to migrate from version %d: %w", m.from, err)
+		}
+	}
+	return data, nil
+}
+
+// fileVersion returns the format version of raw genp.yaml content.
+// Files without a header predate versioning: they are version 0 if they
+// still hold the flat password map, or version 1 otherwise.
+func fileVersion(data []byte) (int, error) {
+	var header struct {
+		Version  int       `yaml:"version"`
+		Password yaml.Node `yaml:"password"`
+	}
+	if err := yaml.Unmarshal(data, &header); err != nil {
+		// Only the legacy string-concatenating writer produced files
+		// that are not valid YAML (duplicate keys)
+		return 0, nil
+	}
+
+	switch {
+	case header.Version > CurrentVersion:
+		return 0, fmt.Errorf("%w (format version %d, this genp supports up to %d); please upgrade genp", ErrNewerVersion, header.Version, CurrentVersion)
+	case header.Version > 0:
+		return header.Version, nil
+	case !header.Password.IsZero():
+		return 0, nil
+	default:
+		return 1, nil
+	}
+}
+
+// stampVersion sets the top-level version header of data.
+func stampVersion(data []byte, version int) ([]byte, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+	doc["version"] = version
+	return yaml.Marshal(doc)
+}
+
+// migrateFlatPasswords is the v0 -> v1 step. It moves the flat name ->
+// ciphertext map into structured entries; entries that already exist win
+// over legacy values with the same name. Files with duplicate keys are read
+// with parseDuplicateKeyYAML, which keeps the last value for each key.
+func migrateFlatPasswords(data []byte) ([]byte, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// Refuse files that are neither YAML nor a legacy password list
+		// rather than "migrating" them into an empty vault
+		passwords, dedupErr := parseDuplicateKeyYAML(data)
+		if dedupErr != nil || len(passwords) == 0 {
+			return nil, err
+		}
+		doc = map[string]any{"password": passwords}
+	}
+	if doc == nil {
+		doc = make(map[string]any)
+	}
+
+	entries, _ := doc["entries"].(map[string]any)
+	if entries == nil {
+		entries = make(map[string]any)
+	}
+
+	switch passwords := doc["password"].(type) {
+	case map[string]any:
+		for name, encrypted := range passwords {
+			if _, exists := entries[name]; !exists {
+				entries[name] = map[string]any{"password": encrypted}
+			}
+		}
+	case map[string]string:
+		for name, encrypted := range passwords {
+			if _, exists := entries[name]; !exists {
+				entries[name] = map[string]any{"password": encrypted}
+			}
+		}
+	}
+
+	delete(doc, "password")
+	doc["entries"] = entries
+	return yaml.Marshal(doc)
+}
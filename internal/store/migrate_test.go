@@ -0,0 +1,117 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFileVersion(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want int
+	}{
+		{"flat password map", "password:\n    github: b2xk\n", 0},
+		{"duplicate keys", "password:\n  github: b2xk\npassword:\n  github: bmV3\n", 0},
+		{"entries without header", "entries:\n    github:\n        password: b2xk\n", 1},
+		{"current header", "version: 1\nentries: {}\n", 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fileVersion([]byte(tt.data))
+			if err != nil || got != tt.want {
+				t.Fatalf("fileVersion = %d, %v; want %d", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadConfigFileRefusesNewerVersion(t *testing.T) {
+	confPath := filepath.Join(t.TempDir(), "genp.yaml")
+	future := "version: 99\nentries: {}\nvaults: {}\n"
+	if err := os.WriteFile(confPath, []byte(future), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := loadConfigFile(confPath); !errors.Is(err, ErrNewerVersion) {
+		t.Fatalf("Expected ErrNewerVersion, got %v", err)
+	}
+	if _, err := planMigration(confPath); !errors.Is(err, ErrNewerVersion) {
+		t.Fatalf("Expected ErrNewerVersion from the migration check, got %v", err)
+	}
+	data, _ := os.ReadFile(confPath)
+	if string(data) != future {
+		t.Fatalf("A newer config file must be left untouched, got:\n%s", data)
+	}
+}
+
+func TestPlanMigrationDoesNotWrite(t *testing.T) {
+	confPath := filepath.Join(t.TempDir(), "genp.yaml")
+	legacy := "password:\n    github: b2xk\n"
+	if err := os.WriteFile(confPath, []byte(legacy), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	plan, err := planMigration(confPath)
+	if err != nil {
+		t.Fatalf("planMigration failed: %v", err)
+	}
+	if plan.From != 0 || plan.To != CurrentVersion || len(plan.Steps) != CurrentVersion {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+	data, _ := os.ReadFile(confPath)
+	if string(data) != legacy {
+		t.Fatalf("planMigration must not change the file, got:\n%s", data)
+	}
+}
+
+func TestUpgradeConfigKeepsExistingEntries(t *testing.T) {
+	mixed := "entries:\n    github:\n        password: bmV3\n        username: dXNlcg==\npassword:\n    github: b2xk\n    gitlab: Z2w=\npolicies:\n    short:\n        length: 8\n"
+
+	data, err := upgradeConfig([]byte(mixed), 0)
+	if err != nil {
+		t.Fatalf("upgradeConfig failed: %v", err)
+	}
+	if !strings.Contains(string(data), "version: 1") {
+		t.Fatalf("Upgraded config has no version header:\n%s", data)
+	}
+
+	confPath := filepath.Join(t.TempDir(), "genp.yaml")
+	if err := os.WriteFile(confPath, []byte(mixed), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if got := cfg.Entries["github"]; got.Password != "bmV3" || got.Username != "dXNlcg==" {
+		t.Fatalf("Existing entry should win over the legacy value, got %+v", got)
+	}
+	if cfg.Entries["gitlab"] == nil || cfg.Policies["short"].Length != 8 {
+		t.Fatalf("Legacy entry or policy lost: %+v", cfg)
+	}
+}
+
+func TestLoadConfigFileRejectsGarbage(t *testing.T) {
+	confPath := filepath.Join(t.TempDir(), "genp.yaml")
+	garbage := "entries: [unclosed\n"
+	if err := os.WriteFile(confPath, []byte(garbage), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	if _, err := loadConfigFile(confPath); err == nil {
+		t.Fatal("Expected an error for an unparsable config file")
+	}
+	data, _ := os.ReadFile(confPath)
+	if string(data) != garbage {
+		t.Fatalf("An unparsable config file must be left untouched, got:\n%s", data)
+	}
+}
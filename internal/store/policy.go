@@ -0,0 +1,110 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"github.com/mdxabu/genp/internal/config"
+)
+
+// Policy is a named set of password generation rules kept in the
+// "policies" section of genp.yaml. Policies are shared by all vaults, so
+// they always live in the default vault's file.
+type Policy struct {
+	Length    int  `yaml:"length"`
+	Numbers   bool `yaml:"numbers,omitempty"`
+	Uppercase bool `yaml:"uppercase,omitempty"`
+	Special   bool `yaml:"special,omitempty"`
+
+	MinLower   int `yaml:"min_lower,omitempty"`
+	MinUpper   int `yaml:"min_upper,omitempty"`
+	MinDigits  int `yaml:"min_digits,omitempty"`
+	MinSpecial int `yaml:"min_special,omitempty"`
+
+	Charset     string `yaml:"charset,omitempty"`
+	Symbols     string `yaml:"symbols,omitempty"`
+	Exclude     string `yaml:"exclude,omitempty"`
+	NoAmbiguous bool   `yaml:"no_ambiguous,omitempty"`
+}
+
+// GetPolicies returns all policies defined in the config file.
+// A missing config file simply yields no policies.
+func GetPolicies() (map[string]Policy, error) {
+	confPath, err := config.ConfigFilePath(runtime.GOOS)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine config file path: %w", err)
+	}
+
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.Policies == nil {
+		return map[string]Policy{}, nil
+	}
+	return cfg.Policies, nil
+}
+
+// GetPolicy returns the policy with the given name.
+func GetPolicy(name string) (Policy, error) {
+	policies, err := GetPolicies()
+	if err != nil {
+		return Policy{}, err
+	}
+
+	policy, ok := policies[name]
+	if !ok {
+		return Policy{}, fmt.Errorf("policy %q does not exist", name)
+	}
+	return policy, nil
+}
+
+// SavePolicy adds or replaces a named policy in the config file.
+// It reports whether an existing policy was replaced.
+func SavePolicy(name string, policy Policy, osName string) (string, bool, error) {
+	if name == "" {
+		return "", false, errors.New("policy name must not be empty")
+	}
+
+	confPath, err := vaultPath(osName, DefaultVault)
+	if err != nil {
+		return "", false, err
+	}
+
+	replaced := false
+	err = updateConfigAt(confPath, func(cfg *ConfigFile) error {
+		if cfg.Policies == nil {
+			cfg.Policies = make(map[string]Policy)
+		}
+		_, replaced = cfg.Policies[name]
+		cfg.Policies[name] = policy
+		return nil
+	})
+	if err != nil {
+		return "", false, err
+	}
+	return confPath, replaced, nil
+}
+
+// RemovePolicy deletes a named policy from the config file.
+func RemovePolicy(name string, osName string) (string, error) {
+	confPath, err := vaultPath(osName, DefaultVault)
+	if err != nil {
+		return "", err
+	}
+
+	return confPath, updateConfigAt(confPath, func(cfg *ConfigFile) error {
+		if _, ok := cfg.Policies[name]; !ok {
+			return fmt.Errorf("policy %q does not exist", name)
+		}
+		delete(cfg.Policies, name)
+		return nil
+	})
+}
@@ -0,0 +1,78 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"testing"
+)
+
+func TestSaveAndRemovePolicies(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A missing config file has no policies
+	policies, err := GetPolicies()
+	if err != nil || len(policies) != 0 {
+		t.Fatalf("GetPolicies of a new config = %+v, %v", policies, err)
+	}
+
+	if _, _, err := SavePolicy("", Policy{Length: 16}, "linux"); err == nil {
+		t.Fatal("SavePolicy accepted an empty name")
+	}
+	aws := Policy{Length: 24, Numbers: true, Special: true, MinDigits: 2, Exclude: `'"`}
+	if _, replaced, err := SavePolicy("aws", aws, "linux"); err != nil || replaced {
+		t.Fatalf("SavePolicy = %v, %v; want a new policy", replaced, err)
+	}
+	if _, _, err := SavePolicy("pin", Policy{Length: 6, Charset: "0123456789"}, "linux"); err != nil {
+		t.Fatalf("SavePolicy failed: %v", err)
+	}
+	aws.Length = 32
+	if _, replaced, err := SavePolicy("aws", aws, "linux"); err != nil || !replaced {
+		t.Fatalf("SavePolicy = %v, %v; want the policy replaced", replaced, err)
+	}
+
+	policies, err = GetPolicies()
+	if err != nil || len(policies) != 2 {
+		t.Fatalf("GetPolicies = %+v, %v", policies, err)
+	}
+	if got, err := GetPolicy("aws"); err != nil || got != aws {
+		t.Fatalf("GetPolicy = %+v, %v; want %+v", got, err, aws)
+	}
+
+	if _, err := RemovePolicy("aws", "linux"); err != nil {
+		t.Fatalf("RemovePolicy failed: %v", err)
+	}
+	if _, err := RemovePolicy("aws", "linux"); err == nil {
+		t.Fatal("RemovePolicy of a missing policy should fail")
+	}
+	if _, err := GetPolicy("aws"); err == nil {
+		t.Fatal("GetPolicy found a removed policy")
+	}
+	if policies, _ := GetPolicies(); len(policies) != 1 {
+		t.Fatalf("GetPolicies after remove = %+v", policies)
+	}
+}
+
+func TestPoliciesAreSharedByVaults(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := CreateVault("work", "work-master", SyncTarget{}, "linux"); err != nil {
+		t.Fatalf("CreateVault failed: %v", err)
+	}
+	useVault(t, "work")
+
+	// Policies saved while another vault is active go to the default one
+	if _, _, err := SavePolicy("aws", Policy{Length: 24}, "linux"); err != nil {
+		t.Fatalf("SavePolicy failed: %v", err)
+	}
+	activeVault = DefaultVault
+	if _, err := GetPolicy("aws"); err != nil {
+		t.Fatalf("GetPolicy from the default vault failed: %v", err)
+	}
+	useVault(t, "work")
+	if _, err := RemovePolicy("aws", "linux"); err != nil {
+		t.Fatalf("RemovePolicy failed: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"fmt"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+// RekeyVault protects the active vault with the given KDF parameters and
+// records them in settings.kdf, so everything encrypted later uses them
+// too. The vault key and the key check are re-wrapped; entries are already
+// encrypted with the vault key and only those still encrypted with the
+// master password are re-encrypted. If any of them fails to decrypt, the
+// vault is left unchanged. It returns the vault path and the number of
+// entries re-encrypted.
+func RekeyVault(key *VaultKey, params crypto.KDFParams, osName string) (string, int, error) {
+	if err := params.Validate(); err != nil {
+		return "", 0, err
+	}
+
+	var count int
+	confPath, err := updateConfig(osName, func(cfg *ConfigFile) error {
+		// Never wrap a key that is not the vault's own
+		if cfg.Vault.Key != "" || key.dek != nil {
+			if err := verifyVaultKey(cfg, key); err != nil {
+				return err
+			}
+		}
+		if cfg.Vault.KeyCheck != "" {
+			if err := cfg.Vault.checkMasterPassword(key.masterPassword); err != nil {
+				return fmt.Errorf("%w %s", err, activeVault)
+			}
+			keyCheck, err := crypto.EncryptWithKDF(keyCheckPlaintext, key.masterPassword, params)
+			if err != nil {
+				return fmt.Errorf("failed to encrypt key check: %w", err)
+			}
+			cfg.Vault.KeyCheck = keyCheck
+		}
+		if key.dek != nil {
+			wrapped, err := key.wrap(params)
+			if err != nil {
+				return err
+			}
+			cfg.Vault.Key = wrapped
+		}
+
+		count = 0
+		for name, entry := range cfg.Entries {
+			changed, err := entry.reencrypt(key, params)
+			if err != nil {
+				return fmt.Errorf("failed to re-encrypt %q: %w", name, err)
+			}
+			if changed {
+				count++
+			}
+		}
+		cfg.Settings.KDF = params
+		return nil
+	})
+	if err != nil {
+		return "", 0, err
+	}
+
+	if err := crypto.SetDefaultKDF(params); err != nil {
+		return "", 0, fmt.Errorf("invalid kdf setting: %w", err)
+	}
+	return confPath, count, nil
+}
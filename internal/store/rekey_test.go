@@ -0,0 +1,129 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+func TestRekeyVaultRewrapsTheVaultKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { crypto.SetDefaultKDF(crypto.DefaultArgon2idParams) })
+
+	master := "TestMasterPassword123!"
+	key, err := NewVaultKey(master)
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+	if _, err := updateConfig("linux", func(cfg *ConfigFile) error {
+		cfg.Vault.Key, err = key.wrap(crypto.KDFParams{Algorithm: crypto.KDFPBKDF2, Iterations: 1000})
+		return err
+	}); err != nil {
+		t.Fatalf("Failed to store the vault key: %v", err)
+	}
+	entry, err := NewEntry("github", EntryDetails{Password: "current", Username: "alice"}, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+	// An earlier password from before the vault had a vault key
+	legacy, err := crypto.EncryptWithKDF("first", master, crypto.LegacyPBKDF2Params)
+	if err != nil {
+		t.Fatalf("EncryptWithKDF failed: %v", err)
+	}
+	entry.History = []HistoryItem{{Password: legacy, Entry: &EntrySnapshot{Username: legacy}}}
+	if _, err := StoreEntry("github", entry, "linux"); err != nil {
+		t.Fatalf("StoreEntry failed: %v", err)
+	}
+	currentCiphertext := entry.Password
+
+	// A key with another DEK must not replace the vault's own
+	foreign, err := NewVaultKey(master)
+	if err != nil {
+		t.Fatalf("NewVaultKey failed: %v", err)
+	}
+	if _, _, err := RekeyVault(foreign, crypto.DefaultPBKDF2Params, "linux"); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Fatalf("Expected ErrWrongMasterPassword for a foreign vault key, got %v", err)
+	}
+
+	target := crypto.KDFParams{Algorithm: crypto.KDFArgon2id, Memory: 64, Time: 1, Parallelism: 1}
+	confPath, count, err := RekeyVault(key, target, "linux")
+	if err != nil || count != 1 {
+		t.Fatalf("RekeyVault = %d, %v", count, err)
+	}
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if cfg.Settings.KDF != target {
+		t.Fatalf("settings.kdf = %+v, want %+v", cfg.Settings.KDF, target)
+	}
+	if params, err := crypto.KDFOf(cfg.Vault.Key); err != nil || params != target {
+		t.Fatalf("Vault key wrapped with %+v, %v; want %+v", params, err, target)
+	}
+
+	// Entries stay encrypted with the vault key; only the legacy one moved
+	rekeyed := cfg.Entries["github"]
+	if rekeyed.Password != currentCiphertext {
+		t.Fatal("RekeyVault re-encrypted a ciphertext already using the vault key")
+	}
+	if !crypto.UsesKey(rekeyed.History[0].Password) || !crypto.UsesKey(rekeyed.History[0].Entry.Username) {
+		t.Fatal("RekeyVault left a ciphertext encrypted with the master password")
+	}
+	unlocked, err := unlockKey(cfg.Vault, master)
+	if err != nil {
+		t.Fatalf("unlockKey failed: %v", err)
+	}
+	previous, err := rekeyed.VersionPassword(1, unlocked)
+	if err != nil || previous != "first" {
+		t.Fatalf("History after rekey = %q, %v", previous, err)
+	}
+}
+
+func TestRekeyVaultWithoutVaultKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Cleanup(func() { crypto.SetDefaultKDF(crypto.DefaultArgon2idParams) })
+
+	// A vault whose entries could not be moved to a vault key is rekeyed by
+	// re-encrypting every ciphertext with the master password
+	key := &VaultKey{masterPassword: "TestMasterPassword123!"}
+	legacy := crypto.KDFParams{Algorithm: crypto.KDFPBKDF2, Iterations: 1000}
+	if err := crypto.SetDefaultKDF(legacy); err != nil {
+		t.Fatalf("SetDefaultKDF failed: %v", err)
+	}
+	entry, err := NewEntry("github", EntryDetails{Password: "secret"}, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+	if _, err := StoreEntry("github", entry, "linux"); err != nil {
+		t.Fatalf("StoreEntry failed: %v", err)
+	}
+
+	if _, _, err := RekeyVault(&VaultKey{masterPassword: "wrong"}, crypto.DefaultPBKDF2Params, "linux"); err == nil {
+		t.Fatal("RekeyVault with the wrong master password should fail")
+	}
+	unchanged, err := GetEntry("github")
+	if err != nil || unchanged.Password != entry.Password {
+		t.Fatalf("Failed rekey changed the entry: %+v, %v", unchanged, err)
+	}
+
+	target := crypto.KDFParams{Algorithm: crypto.KDFArgon2id, Memory: 64, Time: 1, Parallelism: 1}
+	if _, count, err := RekeyVault(key, target, "linux"); err != nil || count != 1 {
+		t.Fatalf("RekeyVault = %d, %v", count, err)
+	}
+	rekeyed, err := GetEntry("github")
+	if err != nil {
+		t.Fatalf("GetEntry failed: %v", err)
+	}
+	if params, err := crypto.KDFOf(rekeyed.Password); err != nil || params != target {
+		t.Fatalf("Password uses %+v, %v; want %+v", params, err, target)
+	}
+	if password, err := rekeyed.Field(FieldPassword, key); err != nil || password != "secret" {
+		t.Fatalf("Decrypt after rekey = %q, %v", password, err)
+	}
+}
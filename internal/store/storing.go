@@ -5,40 +5,171 @@ Copyright 2025 - github.com/mdxabu
 package store
 
 import (
-	"fmt"
+	"errors"
+	"os"
 	"runtime"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mdxabu/genp/internal/crypto"
 )
 
-func StorepasswordLocally(password string) string {
-	var passwordName string
-	color.New(color.FgCyan).Print("Enter a name for the password: ")
-	fmt.Scanln(&passwordName)
+// errStoreCancelled is returned when the user cancels storing a password.
+var errStoreCancelled = errors.New("cancelled")
+
+// StorepasswordLocally prompts for a name and details, encrypts them and
+// stores the entry. If the name is taken the user chooses to overwrite,
+// rename or keep both, unless force overwrites right away. An overwritten
+// password is kept in the entry's history.
+func StorepasswordLocally(password string, force bool) string {
+	passwordName := PromptLine("Enter a name for the password: ")
+	// Check the name before asking for anything else
+	if err := ValidateEntryName(passwordName); err != nil {
+		color.Red("Failed to store password locally: %v\n", err)
+		return ""
+	}
+	overwrite := force
+	if !force {
+		var err error
+		passwordName, overwrite, err = chooseEntryName(passwordName)
+		if errors.Is(err, errStoreCancelled) {
+			color.Yellow("Password not stored.\n")
+			return ""
+		}
+		if err != nil {
+			color.Red("Failed to store password locally: %v\n", err)
+			return ""
+		}
+	}
+
+	// Optional details; pressing Enter skips a field
+	color.Cyan("Optional details (press Enter to skip):\n")
+	details := EntryDetails{
+		Password: password,
+		Username: PromptLine("  Username: "),
+		URLs:     SplitList(PromptLine("  URL(s), comma separated: ")),
+		Notes:    PromptLine("  Notes: "),
+		Tags:     SplitList(PromptLine("  Tags, comma separated: ")),
+	}
 
 	OSName := runtime.GOOS
 
-	// Prompt for system password (single prompt, verified against OS)
-	masterPassword, err := crypto.PromptForMasterPassword("Enter system password: ")
+	// Prompt for the vault's master password, or choose one for a new vault
+	key, err := unlockForStore()
 	if err != nil {
 		color.Red("Failed to authenticate: %v\n", err)
 		return ""
 	}
 
-	// Encrypt the password
-	encryptedPassword, err := crypto.Encrypt(password, masterPassword)
+	// Encrypt the password and the sensitive details
+	entry, err := NewEntry(passwordName, details, key)
 	if err != nil {
 		color.Red("Failed to encrypt password: %v\n", err)
 		return ""
 	}
 
-	confPath, err := StoreLocalConfig(passwordName, encryptedPassword, OSName)
+	save := StoreEntry
+	if overwrite {
+		save = ReplaceEntry
+	}
+	confPath, err := save(passwordName, entry, OSName)
 	if err != nil {
 		color.Red("Failed to store password locally: %v\n", err)
 		return ""
 	}
 
 	color.Green("Password encrypted and stored locally at: %s\n", confPath)
+	if len(entry.History) > 0 {
+		color.Yellow("The previous version of %q was kept in its history.\n", passwordName)
+	}
 	return confPath
 }
+
+// unlockForStore unlocks the active vault to store an entry in it. A vault
+// without entries and without a master password of its own gets one first,
+// as with 'genp init', so new vaults are never keyed with the system
+// password.
+func unlockForStore() (*VaultKey, error) {
+	info, err := ActiveVaultInfo()
+	if err != nil {
+		return nil, err
+	}
+	if info.OwnMasterPassword || info.Entries > 0 {
+		return UnlockVault()
+	}
+
+	color.Cyan("Vault %s has no master password yet. Choose one; you will need it to read your passwords.\n", info.Name)
+	masterPassword, err := crypto.PromptForPassword("New master password: ")
+	if err != nil {
+		return nil, err
+	}
+	again, err := crypto.PromptForPassword("Repeat master password: ")
+	if err != nil {
+		return nil, err
+	}
+	if masterPassword != again {
+		return nil, errors.New("passwords do not match")
+	}
+	if _, err := InitMasterPassword(nil, masterPassword, false, runtime.GOOS); err != nil {
+		return nil, err
+	}
+	return UnlockWithPassword(masterPassword)
+}
+
+// chooseEntryName asks what to do while name is already taken. It returns
+// the name to store under and whether the existing entry is overwritten.
+func chooseEntryName(name string) (string, bool, error) {
+	for {
+		_, err := GetEntry(name)
+		if errors.Is(err, ErrEntryNotFound) {
+			return name, false, nil
+		}
+		if err != nil {
+			return "", false, err
+		}
+
+		color.Yellow("An entry named %q already exists.\n", name)
+		switch strings.ToLower(PromptLine("[o]verwrite, [r]ename, [k]eep both or [c]ancel?: ")) {
+		case "o", "overwrite":
+			return name, true, nil
+		case "r", "rename":
+			name = PromptLine("Enter a new name for the password: ")
+			if err := ValidateEntryName(name); err != nil {
+				return "", false, err
+			}
+		case "k", "keep", "keep both":
+			name, err = AvailableName(name)
+			return name, false, err
+		default:
+			return "", false, errStoreCancelled
+		}
+	}
+}
+
+// PromptLine prints label and reads one line from stdin, trimmed.
+// It reads byte by byte so no input meant for later prompts is buffered away.
+func PromptLine(label string) string {
+	color.New(color.FgCyan).Print(label)
+
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n == 0 || err != nil || buf[0] == '\n' {
+			break
+		}
+		line = append(line, buf[0])
+	}
+	return strings.TrimSpace(string(line))
+}
+
+// SplitList splits a comma separated list, dropping empty items.
+func SplitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,252 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/mdxabu/genp/internal/crypto"
+	"github.com/mdxabu/genp/internal/crypto/cryptotest"
+)
+
+// useFakeAuth replaces the system authenticator with fake and answers the
+// password prompts with answers for the rest of the test. It returns the
+// prompts shown so far.
+func useFakeAuth(t *testing.T, fake *cryptotest.FakeAuthenticator, answers ...string) *[]string {
+	t.Helper()
+	previous := newAuthenticator
+	newAuthenticator = func(cfg crypto.AuthConfig) (crypto.Authenticator, error) {
+		if cfg.Method == crypto.AuthSystem {
+			return fake, nil
+		}
+		return crypto.NewAuthenticator(cfg)
+	}
+	prompts := &[]string{}
+	restore := crypto.SetPasswordReader(cryptotest.FakePasswordReader(prompts, answers...))
+	t.Cleanup(func() {
+		newAuthenticator = previous
+		restore()
+	})
+	return prompts
+}
+
+func TestUnlockVaultWithSystemPassword(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	fake := &cryptotest.FakeAuthenticator{Password: "login"}
+	useFakeAuth(t, fake, "nope", "login", "login")
+
+	if _, err := UnlockVault(); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("Expected authentication failure, got %v", err)
+	}
+
+	key, err := UnlockVault()
+	if err != nil {
+		t.Fatalf("UnlockVault failed: %v", err)
+	}
+	entry, err := NewEntry("github", EntryDetails{Password: "secret"}, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+	if _, err := StoreEntry("github", entry, "linux"); err != nil {
+		t.Fatalf("StoreEntry failed: %v", err)
+	}
+
+	// The stored entry reads back after unlocking again
+	key, err = UnlockVault()
+	if err != nil {
+		t.Fatalf("Second UnlockVault failed: %v", err)
+	}
+	stored, _ := GetEntry("github")
+	if password, err := stored.Field(FieldPassword, key); err != nil || password != "secret" {
+		t.Fatalf("Decrypt = %q, %v", password, err)
+	}
+	if fake.Calls != 3 {
+		t.Fatalf("The authenticator was asked %d times, want 3", fake.Calls)
+	}
+}
+
+func TestUnlockVaultWithMasterPassword(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if _, err := InitMasterPassword(nil, "master", true, "linux"); err != nil {
+		t.Fatalf("InitMasterPassword failed: %v", err)
+	}
+
+	// os_verify checks the system password first
+	fake := &cryptotest.FakeAuthenticator{Password: "login"}
+	prompts := useFakeAuth(t, fake, "login", "master", "login", "wrong", "master")
+	if _, err := UnlockVault(); err != nil {
+		t.Fatalf("UnlockVault failed: %v", err)
+	}
+	want := []string{"Enter system password: ", "Enter master password: "}
+	if strings.Join(*prompts, "|") != strings.Join(want, "|") {
+		t.Fatalf("Prompts %q, want %q", *prompts, want)
+	}
+	if _, err := UnlockVault(); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Fatalf("Expected ErrWrongMasterPassword, got %v", err)
+	}
+
+	// With the none authenticator only the master password is asked for
+	if _, err := SetAuth(crypto.AuthConfig{Method: crypto.AuthNone}, "linux"); err != nil {
+		t.Fatalf("SetAuth failed: %v", err)
+	}
+	*prompts = nil
+	if _, err := UnlockVault(); err != nil {
+		t.Fatalf("UnlockVault without authenticator failed: %v", err)
+	}
+	if len(*prompts) != 1 || fake.Calls != 2 {
+		t.Fatalf("Prompts %q and %d authenticator calls after choosing none", *prompts, fake.Calls)
+	}
+	if info, _ := ActiveVaultInfo(); info.Auth != crypto.AuthNone {
+		t.Fatalf("ActiveVaultInfo().Auth = %q, want none", info.Auth)
+	}
+}
+
+func TestFirstStoreChoosesMasterPassword(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A new vault asks for a master password instead of the system password
+	fake := &cryptotest.FakeAuthenticator{Password: "login"}
+	useFakeAuth(t, fake, "master", "typo")
+	if _, err := unlockForStore(); err == nil {
+		t.Fatal("unlockForStore accepted a mistyped repeat")
+	}
+	useFakeAuth(t, fake, "master", "master")
+	key, err := unlockForStore()
+	if err != nil {
+		t.Fatalf("unlockForStore failed: %v", err)
+	}
+	if fake.Calls != 0 || key.masterPassword != "master" {
+		t.Fatalf("The new vault was keyed with %q after %d system checks", key.masterPassword, fake.Calls)
+	}
+	if info, _ := ActiveVaultInfo(); !info.OwnMasterPassword {
+		t.Fatal("The new vault has no master password of its own")
+	}
+
+	// Once it has one, storing unlocks it as usual
+	prompts := useFakeAuth(t, fake, "master")
+	if _, err := unlockForStore(); err != nil {
+		t.Fatalf("Second unlockForStore failed: %v", err)
+	}
+	if len(*prompts) != 1 || (*prompts)[0] != "Enter master password: " {
+		t.Fatalf("Prompts %q, want the master password only", *prompts)
+	}
+}
+
+func TestSetAuthRejectsInvalidSettings(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := SetAuth(crypto.AuthConfig{Method: crypto.AuthKeyfile}, "linux"); err == nil {
+		t.Fatal("SetAuth accepted a keyfile authenticator without a key file")
+	}
+	if _, err := SetAuth(crypto.AuthConfig{Method: "ldap"}, "linux"); err == nil {
+		t.Fatal("SetAuth accepted an unknown authenticator")
+	}
+}
+
+func TestSystemPasswordKeyIsAlwaysChecked(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := SetAuth(crypto.AuthConfig{Method: crypto.AuthNone}, "linux"); !errors.Is(err, ErrNoMasterPassword) {
+		t.Fatalf("Expected ErrNoMasterPassword, got %v", err)
+	}
+
+	// A vault file edited by hand still gets the system password checked
+	if _, err := updateConfig("linux", func(cfg *ConfigFile) error {
+		cfg.Settings.Auth = crypto.AuthConfig{Method: crypto.AuthNone}
+		return nil
+	}); err != nil {
+		t.Fatalf("updateConfig failed: %v", err)
+	}
+	fake := &cryptotest.FakeAuthenticator{Password: "login"}
+	useFakeAuth(t, fake, "typo")
+	if _, err := UnlockVault(); err == nil || !strings.Contains(err.Error(), "authentication failed") {
+		t.Fatalf("Expected authentication failure, got %v", err)
+	}
+	if header, _ := activeHeader(); header.Key != "" {
+		t.Fatal("A vault key was wrapped with an unchecked password")
+	}
+}
+
+func TestUnlockVaultReadOnlyLeavesOlderVaults(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	encrypted, err := crypto.EncryptWithKDF("secret", "login", crypto.LegacyPBKDF2Params)
+	if err != nil {
+		t.Fatalf("EncryptWithKDF failed: %v", err)
+	}
+	confPath, err := StoreLocalConfig("github", encrypted, "linux")
+	if err != nil {
+		t.Fatalf("StoreLocalConfig failed: %v", err)
+	}
+	before, _ := os.ReadFile(confPath)
+
+	useFakeAuth(t, &cryptotest.FakeAuthenticator{Password: "login"}, "login", "login")
+	key, err := UnlockVaultReadOnly()
+	if err != nil {
+		t.Fatalf("UnlockVaultReadOnly failed: %v", err)
+	}
+	entry, _ := GetEntry("github")
+	if password, err := entry.Field(FieldPassword, key); err != nil || password != "secret" {
+		t.Fatalf("Field = %q, %v", password, err)
+	}
+	if after, _ := os.ReadFile(confPath); string(after) != string(before) {
+		t.Fatal("A read-only unlock rewrote the vault")
+	}
+
+	// Commands that write move the vault to a vault key
+	if _, err := UnlockVault(); err != nil {
+		t.Fatalf("UnlockVault failed: %v", err)
+	}
+	if header, _ := activeHeader(); header.Key == "" {
+		t.Fatal("UnlockVault did not give the vault a vault key")
+	}
+}
+
+func TestEditAfterUpgradingOlderVault(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	encrypted, err := crypto.EncryptWithKDF("secret", "login", crypto.LegacyPBKDF2Params)
+	if err != nil {
+		t.Fatalf("EncryptWithKDF failed: %v", err)
+	}
+	if _, err := StoreLocalConfig("github", encrypted, "linux"); err != nil {
+		t.Fatalf("StoreLocalConfig failed: %v", err)
+	}
+	useFakeAuth(t, &cryptotest.FakeAuthenticator{Password: "login"}, "login")
+
+	// The flow of 'genp edit': unlocking moves the vault to a vault key and
+	// re-encrypts the entry, so it is read after unlocking
+	stale, _ := GetEntry("github")
+	key, err := UnlockVault()
+	if err != nil {
+		t.Fatalf("UnlockVault failed: %v", err)
+	}
+	name, entry, err := FindEntry("github", Filter{})
+	if err != nil {
+		t.Fatalf("FindEntry failed: %v", err)
+	}
+	if entry.sameVersion(stale) {
+		t.Fatal("Unlocking did not re-encrypt the entry")
+	}
+	details, err := entry.Decrypt(key)
+	if err != nil {
+		t.Fatalf("Decrypt failed: %v", err)
+	}
+	details.Username = "alice"
+
+	read := *entry
+	if err := entry.Update(details, key); err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	if _, err := UpdateEntry(name, &read, entry, "linux"); err != nil {
+		t.Fatalf("UpdateEntry after upgrading the vault failed: %v", err)
+	}
+	stored, _ := GetEntry("github")
+	if username, err := stored.Field(FieldUsername, key); err != nil || username != "alice" {
+		t.Fatalf("Username after edit = %q, %v", username, err)
+	}
+}
@@ -0,0 +1,484 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/config"
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+const (
+	// DefaultVault names the vault stored in genp.yaml itself.
+	DefaultVault = "default"
+	// VaultEnv selects the vault when --vault is not given.
+	VaultEnv = "GENP_VAULT"
+	// keyCheckPlaintext is encrypted with a vault's own master password so
+	// a wrong password is detected before anything is encrypted with it.
+	keyCheckPlaintext = "genp-vault-key-check"
+)
+
+var (
+	// ErrVaultNotFound is returned when a named vault has not been created.
+	ErrVaultNotFound = errors.New("no vault found with name")
+	// ErrVaultExists is returned when creating a vault that already exists.
+	ErrVaultExists = errors.New("a vault already exists with name")
+	// ErrWrongMasterPassword is returned when a vault's master password
+	// does not match its key check.
+	ErrWrongMasterPassword = errors.New("wrong master password for vault")
+	// ErrMasterPasswordSet is returned when setting up a master password
+	// for a vault that already has one.
+	ErrMasterPasswordSet = errors.New("a master password is already set for vault")
+	// ErrNoMasterPassword is returned when changing the master password of
+	// a vault still unlocked with the system password.
+	ErrNoMasterPassword = errors.New("no master password is set for vault")
+)
+
+// vaultNamePattern restricts vault names to what is safe as a file name.
+var vaultNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,63}$`)
+
+// activeVault is the vault used by every store function. See SelectVault.
+var activeVault = DefaultVault
+
+// VaultHeader holds the per-vault settings kept at the top of a vault file.
+type VaultHeader struct {
+	// KeyCheck is a known value encrypted with the vault's own master
+	// password. It is empty for vaults still unlocked with the system
+	// password, i.e. before 'genp init'.
+	KeyCheck string `yaml:"key_check,omitempty"`
+	// Key is the vault's data-encryption key wrapped by the master
+	// password, see VaultKey. It is added when an older vault is unlocked.
+	Key  string     `yaml:"key,omitempty"`
+	Sync SyncTarget `yaml:"sync,omitempty"`
+}
+
+// SyncTarget says where a vault is synced to when logged in to GitHub.
+// Empty fields fall back to the genp-vault repository; see ActiveSyncTarget.
+type SyncTarget struct {
+	Repo     string `yaml:"repo,omitempty"`
+	Path     string `yaml:"path,omitempty"`
+	Disabled bool   `yaml:"disabled,omitempty"`
+}
+
+// VaultInfo describes a vault for 'genp vault list'.
+type VaultInfo struct {
+	Name              string
+	Path              string
+	Entries           int
+	OwnMasterPassword bool
+	Auth              string
+	Sync              SyncTarget
+	// Err is set if the vault could not be read; only Name and Path are
+	// filled in then.
+	Err error
+}
+
+// ValidateVaultName reports whether name can be used for a vault.
+func ValidateVaultName(name string) error {
+	if !vaultNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid vault name %q: use up to 64 letters, digits, '-' or '_'", name)
+	}
+	return nil
+}
+
+// SelectVault makes name the vault used by every store function. An
+// empty name selects the default vault. Whether a named vault exists is
+// checked when it is first used, so 'genp vault create' can run with the
+// vault it creates selected.
+func SelectVault(name string) error {
+	if name == "" {
+		name = DefaultVault
+	}
+	if err := ValidateVaultName(name); err != nil {
+		return err
+	}
+	activeVault = name
+	return nil
+}
+
+// ActiveVault returns the name of the selected vault.
+func ActiveVault() string {
+	return activeVault
+}
+
+// CreateVault creates an empty named vault protected by its own master
+// password, which is never stored: only a key check and the vault key
+// encrypted with it.
+func CreateVault(name string, masterPassword string, sync SyncTarget, osName string) (string, error) {
+	if err := ValidateVaultName(name); err != nil {
+		return "", err
+	}
+	if name == DefaultVault {
+		return "", fmt.Errorf("%w: %q", ErrVaultExists, name)
+	}
+
+	baseDir, err := ConfigBaseDir("genp", osName)
+	if err != nil {
+		return "", err
+	}
+	vaultsDir := filepath.Join(baseDir, config.VaultsDirName)
+	if err := os.MkdirAll(vaultsDir, 0o700); err != nil {
+		return "", fmt.Errorf("failed to create vaults directory %s: %w", vaultsDir, err)
+	}
+
+	keyCheck, err := crypto.Encrypt(keyCheckPlaintext, masterPassword)
+	if err != nil {
+		return "", fmt.Errorf("failed to encrypt key check: %w", err)
+	}
+	key, err := NewVaultKey(masterPassword)
+	if err != nil {
+		return "", err
+	}
+	wrapped, err := key.wrap(crypto.DefaultKDF())
+	if err != nil {
+		return "", err
+	}
+
+	confPath := filepath.Join(vaultsDir, name+config.VaultFileExt)
+	err = updateConfigAt(confPath, func(cfg *ConfigFile) error {
+		if _, statErr := os.Stat(confPath); statErr == nil {
+			return fmt.Errorf("%w: %q", ErrVaultExists, name)
+		}
+		cfg.Vault = VaultHeader{KeyCheck: keyCheck, Key: wrapped, Sync: sync}
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return confPath, nil
+}
+
+// DeleteVault removes a named vault file and its backup. The default vault
+// cannot be deleted.
+func DeleteVault(name string, osName string) (string, error) {
+	if err := ValidateVaultName(name); err != nil {
+		return "", err
+	}
+	if name == DefaultVault {
+		return "", errors.New("the default vault cannot be deleted")
+	}
+
+	confPath, err := vaultPath(osName, name)
+	if err != nil {
+		return "", err
+	}
+
+	err = withConfigLock(confPath, func() error {
+		if err := os.Remove(confPath); err != nil {
+			return fmt.Errorf("failed to delete vault %s: %w", confPath, err)
+		}
+		os.Remove(confPath + backupSuffix)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	os.Remove(confPath + lockSuffix)
+	return confPath, nil
+}
+
+// ListVaults returns the default vault followed by the named vaults,
+// sorted by name. A vault that cannot be read is still listed, with its
+// Err set.
+func ListVaults() ([]VaultInfo, error) {
+	osName := runtime.GOOS
+	defaultPath, err := config.ConfigFilePath(osName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to determine config file path: %w", err)
+	}
+	vaults := []VaultInfo{{Name: DefaultVault, Path: defaultPath}}
+
+	vaultsDir, err := config.VaultsDir(osName)
+	if err != nil {
+		return nil, err
+	}
+	files, err := os.ReadDir(vaultsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read vaults directory %s: %w", vaultsDir, err)
+	}
+	for _, f := range files {
+		name, ok := strings.CutSuffix(f.Name(), config.VaultFileExt)
+		if !ok || f.IsDir() || ValidateVaultName(name) != nil {
+			continue
+		}
+		vaults = append(vaults, VaultInfo{Name: name, Path: filepath.Join(vaultsDir, f.Name())})
+	}
+	named := vaults[1:]
+	sort.Slice(named, func(i, j int) bool { return named[i].Name < named[j].Name })
+
+	for i := range vaults {
+		cfg, err := loadConfigFile(vaults[i].Path)
+		if err != nil {
+			vaults[i].Err = err
+			continue
+		}
+		vaults[i].fill(cfg)
+	}
+	return vaults, nil
+}
+
+// ActiveVaultInfo describes the active vault.
+func ActiveVaultInfo() (VaultInfo, error) {
+	confPath, err := GetConfigFilePath()
+	if err != nil {
+		return VaultInfo{}, err
+	}
+	cfg, err := loadConfigFile(confPath)
+	if err != nil {
+		return VaultInfo{}, err
+	}
+	info := VaultInfo{Name: activeVault, Path: confPath}
+	info.fill(cfg)
+	return info, nil
+}
+
+// fill sets the fields of info read from the vault's content.
+func (info *VaultInfo) fill(cfg *ConfigFile) {
+	info.Entries = len(cfg.Entries)
+	info.OwnMasterPassword = cfg.Vault.KeyCheck != ""
+	info.Auth = cfg.authMethod()
+	info.Sync = syncTargetFor(info.Name, cfg.Vault.Sync)
+}
+
+// ActiveSyncTarget returns where the active vault is synced to. Without an
+// explicit target the default vault goes to genp.yaml and a named vault to
+// vaults/<name>.yaml in the genp-vault repository (an empty Repo).
+func ActiveSyncTarget() (SyncTarget, error) {
+	header, err := activeHeader()
+	if err != nil {
+		return SyncTarget{}, err
+	}
+	return syncTargetFor(activeVault, header.Sync), nil
+}
+
+// syncTargetFor fills in the default sync path of a vault.
+func syncTargetFor(vaultName string, target SyncTarget) SyncTarget {
+	if target.Path == "" {
+		target.Path = config.ConfigFileName
+		if vaultName != DefaultVault {
+			target.Path = config.VaultsDirName + "/" + vaultName + config.VaultFileExt
+		}
+	}
+	return target
+}
+
+// UnlockVault verifies the user and prompts for the master password of the
+// active vault, returning the vault key. Vaults without a master password
+// of their own (see InitMasterPassword) are unlocked with the system
+// password. The user is verified by the authenticator configured in
+// settings.auth (see ConfigFile.authenticator); it only checks the user and
+// is never used as a key. The vault's KDF is selected for everything
+// encrypted afterwards. Vaults written by older versions of genp are given
+// a vault key (see addVaultKey), so the caller is expected to save and sync
+// the vault; commands that only read use UnlockVaultReadOnly.
+func UnlockVault() (*VaultKey, error) {
+	return unlockVault(true)
+}
+
+// UnlockVaultReadOnly is UnlockVault without giving older vaults a vault
+// key: their entries are decrypted with the master password instead. Like
+// every read, it still upgrades a file in an older format on disk (see
+// loadConfigFile).
+func UnlockVaultReadOnly() (*VaultKey, error) {
+	return unlockVault(false)
+}
+
+// unlockVault implements UnlockVault, upgrading older vaults if upgrade is
+// set.
+func unlockVault(upgrade bool) (*VaultKey, error) {
+	cfg, err := activeConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyKDF(cfg.Settings); err != nil {
+		return nil, err
+	}
+	auth, err := cfg.authenticator()
+	if err != nil {
+		return nil, err
+	}
+
+	var masterPassword string
+	if cfg.Vault.KeyCheck == "" {
+		// The system password is the master password. An unchecked typo
+		// would become the key, so it is checked by the system
+		// authenticator if the configured one does not need it.
+		if !auth.NeedsPassword() {
+			if err := auth.Verify(""); err != nil {
+				return nil, fmt.Errorf("authentication failed: %w", err)
+			}
+			if auth, err = newAuthenticator(crypto.AuthConfig{Method: crypto.AuthSystem}); err != nil {
+				return nil, err
+			}
+		}
+		if masterPassword, err = crypto.PromptAndVerify("Enter system password: ", auth); err != nil {
+			return nil, err
+		}
+	} else {
+		if _, err := crypto.PromptAndVerify("Enter system password: ", auth); err != nil {
+			return nil, err
+		}
+		if masterPassword, err = crypto.PromptForPassword(masterPasswordPrompt()); err != nil {
+			return nil, err
+		}
+	}
+
+	key, err := unlockConfig(cfg, masterPassword, upgrade)
+	if errors.Is(err, ErrWrongMasterPassword) || cfg.Vault.Key != "" {
+		return key, err
+	}
+	if err != nil {
+		// The password was verified, so entries that still decrypt with it
+		// stay usable
+		color.Yellow("Warning: could not create a vault key, entries stay encrypted with the master password: %v\n", err)
+		return &VaultKey{masterPassword: masterPassword}, nil
+	}
+	return key, nil
+}
+
+// newAuthenticator builds the authenticator of a vault. Tests replace it.
+var newAuthenticator = crypto.NewAuthenticator
+
+// authenticator returns the authenticator that verifies the user before
+// the vault is unlocked: the one selected in settings.auth, or else the
+// system authenticator for vaults unlocked with the system password or
+// with settings.os_verify, and none for the rest.
+func (cfg *ConfigFile) authenticator() (crypto.Authenticator, error) {
+	auth := cfg.Settings.Auth
+	auth.Method = cfg.authMethod()
+	a, err := newAuthenticator(auth)
+	if err != nil {
+		return nil, fmt.Errorf("invalid auth setting: %w", err)
+	}
+	return a, nil
+}
+
+// authMethod returns the name of the authenticator used for the vault.
+func (cfg *ConfigFile) authMethod() string {
+	switch {
+	case cfg.Settings.Auth.Method != "":
+		return cfg.Settings.Auth.Method
+	case cfg.Vault.KeyCheck == "" || cfg.Settings.OSVerify:
+		return crypto.AuthSystem
+	default:
+		return crypto.AuthNone
+	}
+}
+
+// SetAuth selects the authenticator of the active vault. The caller should
+// have verified the user with the new authenticator, so nobody is locked
+// out by a mistake in its settings. Authenticators that do not check the
+// system password are refused while it is the vault's master password.
+func SetAuth(auth crypto.AuthConfig, osName string) (string, error) {
+	a, err := crypto.NewAuthenticator(auth)
+	if err != nil {
+		return "", err
+	}
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		if cfg.Vault.KeyCheck == "" && !a.NeedsPassword() {
+			return fmt.Errorf("the %s authenticator does not check the system password: %w %s (run 'genp init' first)", a.Name(), ErrNoMasterPassword, activeVault)
+		}
+		cfg.Settings.Auth = auth
+		cfg.Settings.OSVerify = false
+		return nil
+	})
+}
+
+// UnlockWithPassword unlocks the active vault with masterPassword without
+// prompting or checking it against the operating system, e.g. with the
+// system password a vault was encrypted with before it was changed.
+func UnlockWithPassword(masterPassword string) (*VaultKey, error) {
+	cfg, err := activeConfig()
+	if err != nil {
+		return nil, err
+	}
+	if err := applyKDF(cfg.Settings); err != nil {
+		return nil, err
+	}
+	return unlockConfig(cfg, masterPassword, true)
+}
+
+// unlockConfig returns the key of the active vault, read into cfg, for
+// masterPassword. Vaults written by older versions of genp get a vault key
+// first (see addVaultKey) if upgrade is set; otherwise their key only holds
+// the master password.
+func unlockConfig(cfg *ConfigFile, masterPassword string, upgrade bool) (*VaultKey, error) {
+	header := cfg.Vault
+	if header.Key != "" {
+		key, err := unlockKey(header, masterPassword)
+		if err != nil {
+			return nil, fmt.Errorf("%w %s", err, activeVault)
+		}
+		return key, nil
+	}
+
+	if header.KeyCheck != "" {
+		if err := header.checkMasterPassword(masterPassword); err != nil {
+			return nil, fmt.Errorf("%w %s", err, activeVault)
+		}
+	}
+	if !upgrade {
+		return &VaultKey{masterPassword: masterPassword}, nil
+	}
+	return addVaultKey(masterPassword, runtime.GOOS)
+}
+
+// applyKDF selects the KDF configured in settings for crypto.Encrypt.
+func applyKDF(settings Settings) error {
+	if settings.KDF.Algorithm == "" {
+		return nil
+	}
+	if err := crypto.SetDefaultKDF(settings.KDF); err != nil {
+		return fmt.Errorf("invalid kdf setting: %w", err)
+	}
+	return nil
+}
+
+// masterPasswordPrompt asks for the master password of the active vault.
+func masterPasswordPrompt() string {
+	if activeVault == DefaultVault {
+		return "Enter master password: "
+	}
+	return fmt.Sprintf("Enter master password for vault %s: ", activeVault)
+}
+
+// checkMasterPassword verifies masterPassword against the key check.
+func (h VaultHeader) checkMasterPassword(masterPassword string) error {
+	plaintext, err := crypto.Decrypt(h.KeyCheck, masterPassword)
+	if err != nil || plaintext != keyCheckPlaintext {
+		return ErrWrongMasterPassword
+	}
+	return nil
+}
+
+// activeHeader reads the header of the active vault. The default vault may
+// not exist yet, in which case its header is empty.
+func activeHeader() (VaultHeader, error) {
+	cfg, err := activeConfig()
+	if err != nil {
+		return VaultHeader{}, err
+	}
+	return cfg.Vault, nil
+}
+
+// activeConfig reads the active vault, which is empty if it does not
+// exist yet.
+func activeConfig() (*ConfigFile, error) {
+	confPath, err := GetConfigFilePath()
+	if err != nil {
+		return nil, err
+	}
+	return loadConfigFile(confPath)
+}
@@ -0,0 +1,141 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// useVault selects a vault for the rest of the test.
+func useVault(t *testing.T, name string) {
+	t.Helper()
+	if err := SelectVault(name); err != nil {
+		t.Fatalf("SelectVault(%q) failed: %v", name, err)
+	}
+	t.Cleanup(func() { activeVault = DefaultVault })
+}
+
+func TestNamedVaultsAreSeparate(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	workPath, err := CreateVault("work", "work-master", SyncTarget{Repo: "work-vault"}, "linux")
+	if err != nil {
+		t.Fatalf("CreateVault failed: %v", err)
+	}
+	if want := filepath.Join(configHome, "genp", "vaults", "work.yaml"); workPath != want {
+		t.Fatalf("Vault created at %s, want %s", workPath, want)
+	}
+	if _, err := CreateVault("work", "other", SyncTarget{}, "linux"); !errors.Is(err, ErrVaultExists) {
+		t.Fatalf("Creating an existing vault should return ErrVaultExists, got %v", err)
+	}
+
+	if _, err := StoreLocalConfig("github", "ZGVmYXVsdA==", "linux"); err != nil {
+		t.Fatalf("StoreLocalConfig failed: %v", err)
+	}
+	useVault(t, "work")
+	if confPath, err := StoreLocalConfig("github", "d29yaw==", "linux"); err != nil || confPath != workPath {
+		t.Fatalf("StoreLocalConfig stored in %s, %v; want %s", confPath, err, workPath)
+	}
+
+	work, err := loadConfigFile(workPath)
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if work.Entries["github"].Password != "d29yaw==" || work.Vault.Sync.Repo != "work-vault" {
+		t.Fatalf("Unexpected work vault: %+v", work)
+	}
+	if err := work.Vault.checkMasterPassword("work-master"); err != nil {
+		t.Fatalf("Key check rejected the vault's master password: %v", err)
+	}
+	if err := work.Vault.checkMasterPassword("wrong"); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Fatalf("Expected ErrWrongMasterPassword, got %v", err)
+	}
+
+	defaultCfg, err := loadConfigFile(filepath.Join(configHome, "genp", "genp.yaml"))
+	if err != nil {
+		t.Fatalf("loadConfigFile failed: %v", err)
+	}
+	if defaultCfg.Entries["github"].Password != "ZGVmYXVsdA==" {
+		t.Fatalf("Default vault was changed: %+v", defaultCfg.Entries["github"])
+	}
+
+	if _, err := DeleteVault(DefaultVault, "linux"); err == nil {
+		t.Fatal("Deleting the default vault should fail")
+	}
+	if _, err := DeleteVault("work", "linux"); err != nil {
+		t.Fatalf("DeleteVault failed: %v", err)
+	}
+	if _, err := StoreLocalConfig("gitlab", "d29yaw==", "linux"); !errors.Is(err, ErrVaultNotFound) {
+		t.Fatalf("Using a deleted vault should return ErrVaultNotFound, got %v", err)
+	}
+}
+
+func TestListVaults(t *testing.T) {
+	configHome := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", configHome)
+
+	for _, name := range []string{"zeta", "alpha"} {
+		if _, err := CreateVault(name, "master", SyncTarget{}, "linux"); err != nil {
+			t.Fatalf("CreateVault(%q) failed: %v", name, err)
+		}
+	}
+	broken := filepath.Join(configHome, "genp", "vaults", "broken.yaml")
+	if err := os.WriteFile(broken, []byte("entries: [not, a, map"), 0600); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	// A vault that cannot be read is listed as such, not fatal
+	vaults, err := ListVaults()
+	if err != nil {
+		t.Fatalf("ListVaults failed: %v", err)
+	}
+	var names []string
+	for _, v := range vaults {
+		names = append(names, v.Name)
+	}
+	if want := []string{DefaultVault, "alpha", "broken", "zeta"}; !slices.Equal(names, want) {
+		t.Fatalf("ListVaults = %q, want %q", names, want)
+	}
+	if vaults[2].Err == nil || vaults[2].Path != broken {
+		t.Fatalf("Broken vault listed as %+v", vaults[2])
+	}
+	for _, i := range []int{0, 1, 3} {
+		if vaults[i].Err != nil {
+			t.Fatalf("Vault %s cannot be read: %v", vaults[i].Name, vaults[i].Err)
+		}
+	}
+	if !vaults[1].OwnMasterPassword || vaults[0].OwnMasterPassword {
+		t.Fatalf("Unexpected vault details: %+v", vaults)
+	}
+}
+
+func TestSelectVaultValidatesName(t *testing.T) {
+	for _, name := range []string{"../etc", "a/b", ".hidden", "with space"} {
+		if err := SelectVault(name); err == nil {
+			t.Errorf("SelectVault(%q) should fail", name)
+		}
+	}
+	if activeVault != DefaultVault {
+		t.Fatalf("An invalid name must not change the active vault, got %q", activeVault)
+	}
+}
+
+func TestSyncTargetDefaults(t *testing.T) {
+	if got := syncTargetFor(DefaultVault, SyncTarget{}); got.Path != "genp.yaml" {
+		t.Errorf("Default vault syncs to %q", got.Path)
+	}
+	if got := syncTargetFor("work", SyncTarget{}); got.Path != "vaults/work.yaml" {
+		t.Errorf("Named vault syncs to %q", got.Path)
+	}
+	if got := syncTargetFor("work", SyncTarget{Repo: "r", Path: "p.yaml"}); got.Repo != "r" || got.Path != "p.yaml" {
+		t.Errorf("Explicit target was overridden: %+v", got)
+	}
+}
@@ -0,0 +1,198 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+// VaultKey unlocks the entries of a vault. Entries are encrypted with the
+// vault's random data-encryption key (DEK), which is kept in the vault
+// header wrapped by a key derived from the master password. Unlocking a
+// vault therefore costs a single KDF run however many entries it has, and
+// changing the master password only re-wraps the DEK. Ciphertexts written
+// before the vault had a DEK are decrypted with the master password.
+type VaultKey struct {
+	dek            []byte
+	masterPassword string
+}
+
+// NewVaultKey returns a key with a fresh random DEK for masterPassword.
+func NewVaultKey(masterPassword string) (*VaultKey, error) {
+	dek, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &VaultKey{dek: dek, masterPassword: masterPassword}, nil
+}
+
+// decrypt decrypts a single ciphertext of the vault. Ciphertexts encrypted
+// with the DEK must have been encrypted with the same additional data ad;
+// older ones encrypted with the master password are not bound to any.
+func (k *VaultKey) decrypt(ciphertext string, ad []byte) (string, error) {
+	if crypto.UsesKey(ciphertext) {
+		if k.dek == nil {
+			return "", errors.New("the vault key is not available")
+		}
+		return crypto.DecryptWithKey(ciphertext, k.dek, ad)
+	}
+	return crypto.Decrypt(ciphertext, k.masterPassword)
+}
+
+// encrypt encrypts plaintext with the DEK, bound to the additional data
+// ad, or with the master password if the vault has no DEK.
+func (k *VaultKey) encrypt(plaintext string, ad []byte) (string, error) {
+	if k.dek == nil {
+		return crypto.Encrypt(plaintext, k.masterPassword)
+	}
+	return crypto.EncryptWithKey(plaintext, k.dek, ad)
+}
+
+// wrap returns the DEK wrapped by the master password with params.
+func (k *VaultKey) wrap(params crypto.KDFParams) (string, error) {
+	wrapped, err := crypto.WrapKey(k.dek, k.masterPassword, params)
+	if err != nil {
+		return "", fmt.Errorf("failed to wrap the vault key: %w", err)
+	}
+	return wrapped, nil
+}
+
+// unlockKey returns the key of a vault for masterPassword, unwrapping the
+// DEK kept in its header. A wrong master password returns
+// ErrWrongMasterPassword.
+func unlockKey(header VaultHeader, masterPassword string) (*VaultKey, error) {
+	key := &VaultKey{masterPassword: masterPassword}
+	if header.Key == "" {
+		return key, nil
+	}
+	dek, err := crypto.UnwrapKey(header.Key, masterPassword)
+	if err != nil {
+		return nil, ErrWrongMasterPassword
+	}
+	key.dek = dek
+	return key, nil
+}
+
+// addVaultKey gives the active vault a DEK and re-encrypts its entries with
+// it. Vaults written before genp used DEKs are upgraded this way the first
+// time they are unlocked. Nothing changes if any entry fails to decrypt.
+func addVaultKey(masterPassword string, osName string) (*VaultKey, error) {
+	var key *VaultKey
+	_, err := updateConfig(osName, func(cfg *ConfigFile) error {
+		// Another genp process may have done it in the meantime
+		if cfg.Vault.Key != "" {
+			var err error
+			key, err = unlockKey(cfg.Vault, masterPassword)
+			return err
+		}
+
+		newKey, err := NewVaultKey(masterPassword)
+		if err != nil {
+			return err
+		}
+		if len(cfg.Entries) > 0 {
+			color.Cyan("Moving vault %s to a vault key (%d entries), this happens once...\n", activeVault, len(cfg.Entries))
+		}
+		for name, entry := range cfg.Entries {
+			if _, err := entry.reencrypt(newKey, cfg.Settings.kdf()); err != nil {
+				return fmt.Errorf("failed to re-encrypt %q: %w", name, err)
+			}
+		}
+		if cfg.Vault.Key, err = newKey.wrap(cfg.Settings.kdf()); err != nil {
+			return err
+		}
+		key = newKey
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// InitMasterPassword gives the active vault its own master password, so it
+// no longer depends on the system password. key is the key the vault was
+// unlocked with; it may be nil for a vault without entries, which gets a
+// new vault key. osVerify sets settings.os_verify.
+func InitMasterPassword(key *VaultKey, newPassword string, osVerify bool, osName string) (string, error) {
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		if cfg.Vault.KeyCheck != "" {
+			return fmt.Errorf("%w %s (change it with 'genp passwd')", ErrMasterPasswordSet, activeVault)
+		}
+		if key == nil {
+			if len(cfg.Entries) > 0 {
+				return fmt.Errorf("vault %s has entries and must be unlocked first", activeVault)
+			}
+			var err error
+			if key, err = NewVaultKey(newPassword); err != nil {
+				return err
+			}
+		} else if err := verifyVaultKey(cfg, key); err != nil {
+			return err
+		}
+
+		cfg.Settings.OSVerify = osVerify
+		return rewrapVault(cfg, key, newPassword)
+	})
+}
+
+// ChangeMasterPassword protects the active vault with a new master password
+// by re-wrapping its DEK. Entries are not re-encrypted, except for any still
+// encrypted with the old master password, which move to the DEK first.
+func ChangeMasterPassword(key *VaultKey, newPassword string, osName string) (string, error) {
+	return updateConfig(osName, func(cfg *ConfigFile) error {
+		if cfg.Vault.KeyCheck == "" {
+			return fmt.Errorf("%w %s (set one with 'genp init')", ErrNoMasterPassword, activeVault)
+		}
+		if err := verifyVaultKey(cfg, key); err != nil {
+			return err
+		}
+		return rewrapVault(cfg, key, newPassword)
+	})
+}
+
+// verifyVaultKey checks that key unlocks the vault read into cfg.
+func verifyVaultKey(cfg *ConfigFile, key *VaultKey) error {
+	if key.dek == nil {
+		return errors.New("the vault has no vault key yet; unlock it once to create one")
+	}
+	current, err := unlockKey(cfg.Vault, key.masterPassword)
+	if err != nil || !bytes.Equal(current.dek, key.dek) {
+		return fmt.Errorf("%w %s", ErrWrongMasterPassword, activeVault)
+	}
+	return nil
+}
+
+// rewrapVault moves every entry of cfg to the vault key and protects the
+// key, and a new key check, with newPassword.
+func rewrapVault(cfg *ConfigFile, key *VaultKey, newPassword string) error {
+	if newPassword == "" {
+		return errors.New("password cannot be empty")
+	}
+	for name, entry := range cfg.Entries {
+		if _, err := entry.reencrypt(key, cfg.Settings.kdf()); err != nil {
+			return fmt.Errorf("failed to re-encrypt %q: %w", name, err)
+		}
+	}
+
+	newKey := &VaultKey{dek: key.dek, masterPassword: newPassword}
+	wrapped, err := newKey.wrap(cfg.Settings.kdf())
+	if err != nil {
+		return err
+	}
+	keyCheck, err := crypto.EncryptWithKDF(keyCheckPlaintext, newPassword, cfg.Settings.kdf())
+	if err != nil {
+		return fmt.Errorf("failed to encrypt key check: %w", err)
+	}
+	cfg.Vault.Key, cfg.Vault.KeyCheck = wrapped, keyCheck
+	key.masterPassword = newPassword
+	return nil
+}
@@ -0,0 +1,175 @@
+/*
+Copyright © 2026 @mdxabu
+
+*/
+
+package store
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/mdxabu/genp/internal/crypto"
+)
+
+func TestAddVaultKeyMovesLegacyEntries(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	master := "TestMasterPassword123!"
+	for name, password := range map[string]string{"github": "one", "gitlab": "two"} {
+		encrypted, err := crypto.EncryptWithKDF(password, master, crypto.LegacyPBKDF2Params)
+		if err != nil {
+			t.Fatalf("EncryptWithKDF failed: %v", err)
+		}
+		if _, err := StoreLocalConfig(name, encrypted, "linux"); err != nil {
+			t.Fatalf("StoreLocalConfig failed: %v", err)
+		}
+	}
+
+	// A wrong password changes nothing
+	if _, err := addVaultKey("wrong", "linux"); err == nil {
+		t.Fatal("addVaultKey with the wrong master password should fail")
+	}
+	if header, _ := activeHeader(); header.Key != "" {
+		t.Fatal("Failed addVaultKey stored a vault key")
+	}
+
+	key, err := addVaultKey(master, "linux")
+	if err != nil {
+		t.Fatalf("addVaultKey failed: %v", err)
+	}
+	entries, err := loadEntries()
+	if err != nil {
+		t.Fatalf("loadEntries failed: %v", err)
+	}
+	for name, want := range map[string]string{"github": "one", "gitlab": "two"} {
+		if !crypto.UsesKey(entries[name].Password) {
+			t.Fatalf("%s is still encrypted with the master password", name)
+		}
+		if got, err := entries[name].Field(FieldPassword, key); err != nil || got != want {
+			t.Fatalf("%s decrypts to %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	// The stored vault key unlocks with the master password only
+	header, err := activeHeader()
+	if err != nil {
+		t.Fatalf("activeHeader failed: %v", err)
+	}
+	if _, err := unlockKey(header, "wrong"); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Fatalf("Expected ErrWrongMasterPassword, got %v", err)
+	}
+	again, err := addVaultKey(master, "linux")
+	if err != nil || string(again.dek) != string(key.dek) {
+		t.Fatalf("A second addVaultKey replaced the vault key: %v", err)
+	}
+}
+
+func TestChangeMasterPasswordRewrapsTheVaultKey(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := CreateVault("work", "old-master", SyncTarget{}, "linux"); err != nil {
+		t.Fatalf("CreateVault failed: %v", err)
+	}
+	useVault(t, "work")
+	header, _ := activeHeader()
+	key, err := unlockKey(header, "old-master")
+	if err != nil || key.dek == nil {
+		t.Fatalf("unlockKey of a new vault = %+v, %v", key, err)
+	}
+	entry, err := NewEntry("github", EntryDetails{Password: "secret"}, key)
+	if err != nil {
+		t.Fatalf("NewEntry failed: %v", err)
+	}
+	if _, err := StoreEntry("github", entry, "linux"); err != nil {
+		t.Fatalf("StoreEntry failed: %v", err)
+	}
+
+	if _, err := ChangeMasterPassword(&VaultKey{dek: key.dek, masterPassword: "wrong"}, "new-master", "linux"); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Fatalf("Expected ErrWrongMasterPassword, got %v", err)
+	}
+	if _, err := ChangeMasterPassword(key, "new-master", "linux"); err != nil {
+		t.Fatalf("ChangeMasterPassword failed: %v", err)
+	}
+
+	header, _ = activeHeader()
+	if _, err := unlockKey(header, "old-master"); err == nil {
+		t.Fatal("The old master password still unlocks the vault")
+	}
+	if err := header.checkMasterPassword("new-master"); err != nil {
+		t.Fatalf("Key check rejects the new master password: %v", err)
+	}
+	unlocked, err := unlockKey(header, "new-master")
+	if err != nil {
+		t.Fatalf("unlockKey with the new master password failed: %v", err)
+	}
+	stored, _ := GetEntry("github")
+	if stored.Password != entry.Password {
+		t.Fatal("ChangeMasterPassword re-encrypted an entry")
+	}
+	if password, err := stored.Field(FieldPassword, unlocked); err != nil || password != "secret" {
+		t.Fatalf("Decrypt after password change = %q, %v", password, err)
+	}
+}
+
+func TestInitMasterPassword(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	// A vault with entries is unlocked with the password it was encrypted
+	// with, which need not be the current system password
+	encrypted, err := crypto.EncryptWithKDF("secret", "old-login", crypto.LegacyPBKDF2Params)
+	if err != nil {
+		t.Fatalf("EncryptWithKDF failed: %v", err)
+	}
+	if _, err := StoreLocalConfig("github", encrypted, "linux"); err != nil {
+		t.Fatalf("StoreLocalConfig failed: %v", err)
+	}
+	if _, err := InitMasterPassword(nil, "vault-master", false, "linux"); err == nil {
+		t.Fatal("InitMasterPassword without unlocking a vault with entries should fail")
+	}
+	if _, err := UnlockWithPassword("wrong"); err == nil {
+		t.Fatal("UnlockWithPassword accepted a wrong password")
+	}
+	key, err := UnlockWithPassword("old-login")
+	if err != nil {
+		t.Fatalf("UnlockWithPassword failed: %v", err)
+	}
+	if _, err := ChangeMasterPassword(key, "vault-master", "linux"); !errors.Is(err, ErrNoMasterPassword) {
+		t.Fatalf("Expected ErrNoMasterPassword before init, got %v", err)
+	}
+
+	if _, err := InitMasterPassword(key, "vault-master", true, "linux"); err != nil {
+		t.Fatalf("InitMasterPassword failed: %v", err)
+	}
+	info, err := ActiveVaultInfo()
+	if err != nil || !info.OwnMasterPassword || info.Auth != crypto.AuthSystem {
+		t.Fatalf("ActiveVaultInfo = %+v, %v", info, err)
+	}
+	if _, err := InitMasterPassword(key, "again", false, "linux"); !errors.Is(err, ErrMasterPasswordSet) {
+		t.Fatalf("Expected ErrMasterPasswordSet, got %v", err)
+	}
+
+	if _, err := UnlockWithPassword("old-login"); !errors.Is(err, ErrWrongMasterPassword) {
+		t.Fatalf("The old system password still unlocks the vault: %v", err)
+	}
+	unlocked, err := UnlockWithPassword("vault-master")
+	if err != nil {
+		t.Fatalf("UnlockWithPassword with the new master password failed: %v", err)
+	}
+	entry, _ := GetEntry("github")
+	if password, err := entry.Field(FieldPassword, unlocked); err != nil || password != "secret" {
+		t.Fatalf("Decrypt after init = %q, %v", password, err)
+	}
+}
+
+func TestInitMasterPasswordOfEmptyVault(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+
+	if _, err := InitMasterPassword(nil, "vault-master", false, "linux"); err != nil {
+		t.Fatalf("InitMasterPassword failed: %v", err)
+	}
+	key, err := UnlockWithPassword("vault-master")
+	if err != nil || key.dek == nil {
+		t.Fatalf("UnlockWithPassword = %+v, %v", key, err)
+	}
+}
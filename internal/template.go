@@ -0,0 +1,139 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// Template placeholders understood by GenerateFromTemplate.
+const (
+	placeholderUpper   = 'X'
+	placeholderLower   = 'x'
+	placeholderDigit   = '#'
+	placeholderSpecial = '$'
+	placeholderAlnum   = '?'
+	placeholderAny     = '*'
+	templateEscape     = '\\'
+)
+
+// templateToken is either a literal or a pool of characters to pick from.
+type templateToken struct {
+	literal string
+	pool    string
+}
+
+// GenerateFromTemplate fills every placeholder in template with a random
+// character and copies everything else literally:
+//
+//	X  uppercase letter
+//	x  lowercase letter
+//	#  digit
+//	$  special character
+//	?  letter or digit
+//	*  letter, digit or special character
+//	\  escapes the next character so it is copied as is
+//
+// The Symbols, Exclude, NoAmbiguous and Rand fields of opts are honoured; the
+// length, class and minimum fields are ignored since the template fixes them.
+func GenerateFromTemplate(template string, opts PasswordOptions) (string, error) {
+	tokens, err := parseTemplate(template, opts)
+	if err != nil {
+		return "", err
+	}
+
+	r := opts.random()
+	var b strings.Builder
+	for _, token := range tokens {
+		if token.pool == "" {
+			b.WriteString(token.literal)
+			continue
+		}
+		idx, err := randomIndex(r, len(token.pool))
+		if err != nil {
+			return "", fmt.Errorf("failed to pick character: %w", err)
+		}
+		b.WriteByte(token.pool[idx])
+	}
+	return b.String(), nil
+}
+
+// TemplateEntropy returns the entropy in bits of a password generated
+// from template with opts.
+func TemplateEntropy(template string, opts PasswordOptions) (float64, error) {
+	tokens, err := parseTemplate(template, opts)
+	if err != nil {
+		return 0, err
+	}
+
+	bits := 0.0
+	for _, token := range tokens {
+		if token.pool != "" {
+			bits += math.Log2(float64(len(token.pool)))
+		}
+	}
+	return bits, nil
+}
+
+// parseTemplate splits template into literals and placeholder pools.
+func parseTemplate(template string, opts PasswordOptions) ([]templateToken, error) {
+	if template == "" {
+		return nil, errors.New("template must not be empty")
+	}
+
+	symbols := specialBytes
+	if opts.Symbols != "" {
+		symbols = opts.Symbols
+	}
+	if err := checkPrintableASCII("symbol set", symbols); err != nil {
+		return nil, err
+	}
+
+	drop := opts.Exclude
+	if opts.NoAmbiguous {
+		drop += ambiguousBytes
+	}
+	pools := map[rune]string{
+		placeholderUpper:   uppercaseBytes,
+		placeholderLower:   lowercaseBytes,
+		placeholderDigit:   numberBytes,
+		placeholderSpecial: symbols,
+		placeholderAlnum:   lowercaseBytes + uppercaseBytes + numberBytes,
+		placeholderAny:     lowercaseBytes + uppercaseBytes + numberBytes + symbols,
+	}
+
+	var tokens []templateToken
+	placeholders := 0
+	escaped := false
+	for _, ch := range template {
+		if escaped {
+			tokens = append(tokens, templateToken{literal: string(ch)})
+			escaped = false
+			continue
+		}
+		if ch == templateEscape {
+			escaped = true
+			continue
+		}
+		pool, ok := pools[ch]
+		if !ok {
+			tokens = append(tokens, templateToken{literal: string(ch)})
+			continue
+		}
+		pool = filterChars(pool, drop)
+		if pool == "" {
+			return nil, fmt.Errorf("every character for placeholder %q is excluded", ch)
+		}
+		tokens = append(tokens, templateToken{pool: pool})
+		placeholders++
+	}
+
+	if escaped {
+		return nil, errors.New("template ends with an unfinished escape")
+	}
+	if placeholders == 0 {
+		return nil, errors.New("template contains no placeholders")
+	}
+	return tokens, nil
+}